@@ -4,7 +4,9 @@ import (
 	"errors"
 	"github.com/OVillas/user-api/util"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/OVillas/user-api/model"
 	"github.com/labstack/echo/v4"
@@ -36,13 +38,26 @@ func (a *authenticationHandler) Login(c echo.Context) error {
 		return c.JSON(http.StatusUnprocessableEntity, err)
 	}
 
-	token, err := a.authenticationService.Login(login)
+	tokenPair, err := a.authenticationService.Login(login, util.ExtractClientInfo(c))
+
+	var loginThrottledError *model.LoginThrottledError
+	if err != nil && errors.As(err, &loginThrottledError) {
+		log.Warn("Too many failed login attempts")
+		retryAfter := int(math.Ceil(loginThrottledError.RetryAfter.Seconds()))
+		c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		return c.JSON(http.StatusTooManyRequests, err.Error())
+	}
 	if err != nil && errors.Is(err, model.ErrPasswordNotMatch) {
 		log.Warn("email or password invalid")
 		return c.NoContent(http.StatusForbidden)
 
 	}
 
+	if err != nil && errors.Is(err, model.ErrScopeNotAllowed) {
+		log.Warn("Scope not allowed for this user")
+		return c.NoContent(http.StatusForbidden)
+	}
+
 	if err != nil && errors.Is(err, model.ErrUserNotFound) {
 		log.Warn("User not found with email: " + login.Email)
 		return c.NoContent(http.StatusNotFound)
@@ -55,7 +70,7 @@ func (a *authenticationHandler) Login(c echo.Context) error {
 	}
 
 	log.Info("login executed successfully")
-	return c.JSON(http.StatusOK, token)
+	return c.JSON(http.StatusOK, tokenPair)
 }
 
 func (a *authenticationHandler) UpdatePassword(c echo.Context) error {
@@ -70,13 +85,13 @@ func (a *authenticationHandler) UpdatePassword(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, err)
 	}
 
-	userIdFromToken, err := util.ExtractUserIdFromToken(c)
+	principal, err := util.GetPrincipal(c)
 	if err != nil {
-		log.Warn("err to get user if from token")
+		log.Warn("err to get principal from context")
 		return c.JSON(http.StatusUnauthorized, err)
 	}
 
-	if userId != userIdFromToken {
+	if userId != principal.Id {
 		log.Warn("you cannot update the data of a user other than yourself")
 		return c.NoContent(http.StatusForbidden)
 	}
@@ -92,18 +107,23 @@ func (a *authenticationHandler) UpdatePassword(c echo.Context) error {
 		return c.JSON(http.StatusUnprocessableEntity, err)
 	}
 
-	err = a.authenticationService.UpdatePassword(userId, updatePassword)
+	err = a.authenticationService.UpdatePassword(userId, principal.SessionId, updatePassword)
 
 	if err != nil && errors.Is(err, model.ErrUserNotFound) {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return c.JSON(http.StatusNotFound, err)
 	}
 
 	if err != nil && errors.Is(err, model.ErrPasswordNotMatch) {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return c.JSON(http.StatusUnauthorized, err)
 	}
 
+	if err != nil {
+		log.Error("Error trying to call update password service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
 	log.Info("UpdatePassword executed successfully")
 	return c.NoContent(http.StatusNoContent)
 }
@@ -131,11 +151,255 @@ func (a *authenticationHandler) ConfirmEmail(c echo.Context) error {
 		return c.NoContent(http.StatusUnauthorized)
 	}
 
+	if err != nil && errors.Is(err, model.ErrOTPAttemptsExceeded) {
+		log.Warn("Too many wrong attempts for confirmation code")
+		return c.NoContent(http.StatusTooManyRequests)
+	}
+
 	if err != nil {
-		log.Error("Errors: ", err)
+		log.Error("Errors: " + err.Error())
 		return c.JSON(http.StatusInternalServerError, err)
 	}
 
 	log.Info("e-mail confirmed successfully")
 	return c.NoContent(http.StatusOK)
 }
+
+func (a *authenticationHandler) ResendConfirmationEmail(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "ResendConfirmationEmail"),
+		slog.String("handler", "authentication"))
+
+	var resendConfirmationEmail model.ResendConfirmationEmail
+	if err := c.Bind(&resendConfirmationEmail); err != nil {
+		log.Warn("Failed to bind resendConfirmationEmail data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := resendConfirmationEmail.Validate(); err != nil {
+		log.Warn("Invalid resendConfirmationEmail data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	err := a.authenticationService.ResendConfirmationEmailCode(resendConfirmationEmail.Email)
+
+	if err != nil && (errors.Is(err, model.ErrResendCooldown) || errors.Is(err, model.ErrResendDailyLimit)) {
+		log.Warn("Too many confirmation code requests")
+		return c.JSON(http.StatusTooManyRequests, err.Error())
+	}
+
+	if err != nil {
+		log.Error("Errors: " + err.Error())
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("ResendConfirmationEmail executed successfully")
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (a *authenticationHandler) Refresh(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "Refresh"),
+		slog.String("handler", "authentication"))
+
+	var refresh model.Refresh
+	if err := c.Bind(&refresh); err != nil {
+		log.Warn("Failed to bind refresh data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := refresh.Validate(); err != nil {
+		log.Warn("Invalid refresh data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	tokenPair, err := a.authenticationService.Refresh(refresh.RefreshToken, refresh.Scopes)
+
+	if err != nil && errors.Is(err, model.ErrScopeNotAllowed) {
+		log.Warn("Requested scopes beyond the session")
+		return c.NoContent(http.StatusForbidden)
+	}
+
+	if err != nil && (errors.Is(err, model.ErrInvalidRefreshToken) || errors.Is(err, model.ErrRefreshTokenReused)) {
+		log.Warn("Invalid, expired or reused refresh token")
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		log.Warn("User not found for refresh token")
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	if err != nil {
+		log.Error("Error trying to call refresh service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("refresh executed successfully")
+	return c.JSON(http.StatusOK, tokenPair)
+}
+
+func (a *authenticationHandler) Logout(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "Logout"),
+		slog.String("handler", "authentication"))
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	var logout model.Logout
+	if err := c.Bind(&logout); err != nil {
+		log.Warn("Failed to bind logout data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := a.authenticationService.Logout(principal.Id, principal.SessionId, principal.TokenId, principal.ExpiresAt, logout.RefreshToken); err != nil {
+		log.Error("Error trying to call logout service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("logout executed successfully")
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (a *authenticationHandler) ForgotPassword(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "ForgotPassword"),
+		slog.String("handler", "authentication"))
+
+	var forgotPassword model.ForgotPassword
+	if err := c.Bind(&forgotPassword); err != nil {
+		log.Warn("Failed to bind forgotPassword data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := forgotPassword.Validate(); err != nil {
+		log.Warn("Invalid forgotPassword data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := a.authenticationService.SendPasswordResetCode(forgotPassword.Email); err != nil {
+		log.Error("Errors: " + err.Error())
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("ForgotPassword executed successfully")
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (a *authenticationHandler) ResetPassword(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "ResetPassword"),
+		slog.String("handler", "authentication"))
+
+	var resetPassword model.ResetPassword
+	if err := c.Bind(&resetPassword); err != nil {
+		log.Warn("Failed to bind resetPassword data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := resetPassword.Validate(); err != nil {
+		log.Warn("Invalid resetPassword data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	err := a.authenticationService.ResetPassword(resetPassword)
+
+	if err != nil && errors.Is(err, model.ErrInvalidOTP) {
+		log.Warn("Expired token or wrong token")
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	if err != nil && errors.Is(err, model.ErrOTPAttemptsExceeded) {
+		log.Warn("Too many wrong attempts for password reset code")
+		return c.NoContent(http.StatusTooManyRequests)
+	}
+
+	if err != nil {
+		log.Error("Errors: " + err.Error())
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("ResetPassword executed successfully")
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (a *authenticationHandler) SendMagicLink(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "SendMagicLink"),
+		slog.String("handler", "authentication"))
+
+	var magicLinkRequest model.MagicLinkRequest
+	if err := c.Bind(&magicLinkRequest); err != nil {
+		log.Warn("Failed to bind magicLinkRequest data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := magicLinkRequest.Validate(); err != nil {
+		log.Warn("Invalid magicLinkRequest data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	err := a.authenticationService.SendMagicLink(magicLinkRequest.Email)
+
+	if err != nil && (errors.Is(err, model.ErrResendCooldown) || errors.Is(err, model.ErrResendDailyLimit)) {
+		log.Warn("Too many magic link requests")
+		return c.JSON(http.StatusTooManyRequests, err.Error())
+	}
+
+	if err != nil {
+		log.Error("Errors: " + err.Error())
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("SendMagicLink executed successfully")
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (a *authenticationHandler) MagicLinkLogin(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "MagicLinkLogin"),
+		slog.String("handler", "authentication"))
+
+	var magicLinkLogin model.MagicLinkLogin
+	if err := c.Bind(&magicLinkLogin); err != nil {
+		log.Warn("Failed to bind magicLinkLogin data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := magicLinkLogin.Validate(); err != nil {
+		log.Warn("Invalid magicLinkLogin data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	tokenPair, err := a.authenticationService.MagicLinkLogin(magicLinkLogin.Token, util.ExtractClientInfo(c))
+
+	var loginThrottledError *model.LoginThrottledError
+	if err != nil && errors.As(err, &loginThrottledError) {
+		log.Warn("Too many failed login attempts")
+		retryAfter := int(math.Ceil(loginThrottledError.RetryAfter.Seconds()))
+		c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		return c.JSON(http.StatusTooManyRequests, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrInvalidMagicLink) {
+		log.Warn("Magic link rejected")
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	if err != nil && errors.Is(err, model.ErrEmailDomainNotAllowed) {
+		log.Warn("Magic link for email domain not allowed")
+		return c.JSON(http.StatusForbidden, err.Error())
+	}
+
+	if err != nil {
+		log.Error("Error trying to call magic link login service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("MagicLinkLogin executed successfully")
+	return c.JSON(http.StatusOK, tokenPair)
+}
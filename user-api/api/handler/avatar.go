@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/labstack/echo/v4"
+)
+
+type avatarHandler struct {
+	avatarService model.AvatarService
+}
+
+func NewAvatarHandler(avatarService model.AvatarService) model.AvatarHandler {
+	return &avatarHandler{
+		avatarService: avatarService,
+	}
+}
+
+func (a *avatarHandler) Upload(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "Upload"),
+		slog.String("handler", "avatar"))
+
+	id := c.Param("id")
+	if err := util.IsValidUUID(id); err != nil {
+		log.Warn("Invalid params")
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	if id != principal.Id && !(principal.HasRole(model.RoleAdmin) && principal.HasScope(model.ScopeAdmin)) {
+		log.Warn("you cannot update the avatar of a user other than yourself")
+		return c.NoContent(http.StatusForbidden)
+	}
+
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		log.Warn("Failed to read avatar from multipart form")
+		return c.JSON(http.StatusBadRequest, "The 'avatar' file is required")
+	}
+
+	if fileHeader.Size > model.AvatarMaxSize {
+		log.Warn("Avatar exceeds the maximum size")
+		return c.JSON(http.StatusRequestEntityTooLarge, model.ErrAvatarTooLarge.Error())
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, model.AvatarMaxSize+1))
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	urls, err := a.avatarService.Upload(id, data)
+
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		log.Warn("User not found to upload avatar")
+		return c.JSON(http.StatusNotFound, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrAvatarTooLarge) {
+		log.Warn("Avatar too large")
+		return c.JSON(http.StatusRequestEntityTooLarge, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrInvalidAvatar) {
+		log.Warn("Invalid avatar")
+		return c.JSON(http.StatusUnsupportedMediaType, err.Error())
+	}
+
+	if err != nil {
+		log.Error("Error trying to call upload avatar service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("Avatar successfully uploaded")
+	return c.JSON(http.StatusOK, urls)
+}
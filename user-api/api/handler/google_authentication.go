@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/labstack/echo/v4"
+)
+
+const oidcStateCookiePath = "/v1/authentication/google"
+
+type googleAuthenticationHandler struct {
+	googleAuthenticationService model.GoogleAuthenticationService
+}
+
+func NewGoogleAuthenticationHandler(googleAuthenticationService model.GoogleAuthenticationService) model.GoogleAuthenticationHandler {
+	return &googleAuthenticationHandler{
+		googleAuthenticationService: googleAuthenticationService,
+	}
+}
+
+func (g *googleAuthenticationHandler) Authorize(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "Authorize"),
+		slog.String("handler", "googleAuthentication"))
+
+	authorization, err := g.googleAuthenticationService.Authorize()
+	if err != nil {
+		log.Error("Error trying to call google authorize service.")
+		return c.JSON(http.StatusBadGateway, err.Error())
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     model.OIDCStateCookieName,
+		Value:    authorization.StateToken,
+		Path:     oidcStateCookiePath,
+		Expires:  authorization.ExpiryTime,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	log.Info("Authorize executed successfully")
+	return c.JSON(http.StatusOK, authorization)
+}
+
+func (g *googleAuthenticationHandler) Callback(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "Callback"),
+		slog.String("handler", "googleAuthentication"))
+
+	var oidcCallback model.OIDCCallback
+	if err := c.Bind(&oidcCallback); err != nil {
+		log.Warn("Failed to bind oidcCallback data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := oidcCallback.Validate(); err != nil {
+		log.Warn("Invalid oidcCallback data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	stateToken := ""
+	if cookie, err := c.Cookie(model.OIDCStateCookieName); err == nil {
+		stateToken = cookie.Value
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     model.OIDCStateCookieName,
+		Path:     oidcStateCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	tokenPair, err := g.googleAuthenticationService.Callback(oidcCallback, stateToken, util.ExtractClientInfo(c))
+
+	if err != nil && (errors.Is(err, model.ErrOIDCInvalidState) ||
+		errors.Is(err, model.ErrOIDCExchange) ||
+		errors.Is(err, model.ErrOIDCInvalidIDToken)) {
+		log.Warn("Google login rejected: " + err.Error())
+		return c.JSON(http.StatusUnauthorized, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrOIDCEmailNotVerified) {
+		log.Warn("Google account without verified email")
+		return c.JSON(http.StatusForbidden, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrOIDCAccountNotLinked) {
+		log.Warn("Google account matches an unconfirmed local account")
+		return c.JSON(http.StatusConflict, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrEmailDomainNotAllowed) {
+		log.Warn("Google account with email domain not allowed")
+		return c.JSON(http.StatusForbidden, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrOIDCDiscovery) {
+		log.Error("Error trying to reach the identity provider.")
+		return c.JSON(http.StatusBadGateway, err.Error())
+	}
+
+	if err != nil {
+		log.Error("Error trying to call google callback service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("Callback executed successfully")
+	return c.JSON(http.StatusOK, tokenPair)
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/labstack/echo/v4"
+)
+
+type keyHandler struct{}
+
+func NewKeyHandler() model.KeyHandler {
+	return &keyHandler{}
+}
+
+func (k *keyHandler) JWKS(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "JWKS"),
+		slog.String("handler", "key"))
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=300")
+
+	log.Info("JWKS executed successfully")
+	return c.JSON(http.StatusOK, util.JWKS())
+}
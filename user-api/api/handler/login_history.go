@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/labstack/echo/v4"
+)
+
+type loginHistoryHandler struct {
+	loginHistoryService model.LoginHistoryService
+}
+
+func NewLoginHistoryHandler(loginHistoryService model.LoginHistoryService) model.LoginHistoryHandler {
+	return &loginHistoryHandler{
+		loginHistoryService: loginHistoryService,
+	}
+}
+
+func (l *loginHistoryHandler) GetByUserId(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "GetByUserId"),
+		slog.String("handler", "loginHistory"))
+
+	id := c.Param("id")
+
+	if err := util.IsValidUUID(id); err != nil {
+		log.Warn("Invalid id")
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	if id != principal.Id {
+		log.Warn("you cannot see the login history of a user other than yourself")
+		return c.NoContent(http.StatusForbidden)
+	}
+
+	loginEvents, err := l.loginHistoryService.GetByUserId(id)
+	if err != nil {
+		log.Error("Error trying to call get login history service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("GetByUserId executed successfully")
+	return c.JSON(http.StatusOK, loginEvents)
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/labstack/echo/v4"
+)
+
+type personalAccessTokenHandler struct {
+	personalAccessTokenService model.PersonalAccessTokenService
+}
+
+func NewPersonalAccessTokenHandler(personalAccessTokenService model.PersonalAccessTokenService) model.PersonalAccessTokenHandler {
+	return &personalAccessTokenHandler{
+		personalAccessTokenService: personalAccessTokenService,
+	}
+}
+
+func (p *personalAccessTokenHandler) Create(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "Create"),
+		slog.String("handler", "personalAccessToken"))
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	var personalAccessTokenPayLoad model.PersonalAccessTokenPayLoad
+	if err := c.Bind(&personalAccessTokenPayLoad); err != nil {
+		log.Warn("Failed to bind personalAccessToken data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := personalAccessTokenPayLoad.Validate(); err != nil {
+		log.Warn("Invalid personalAccessToken data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	personalAccessToken, err := p.personalAccessTokenService.Create(principal.Id, personalAccessTokenPayLoad)
+
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		log.Warn("User not found to create personal access token")
+		return c.JSON(http.StatusNotFound, err)
+	}
+
+	if err != nil && errors.Is(err, model.ErrScopeNotAllowed) {
+		log.Warn("Scope not allowed for this user")
+		return c.NoContent(http.StatusForbidden)
+	}
+
+	if err != nil {
+		log.Error("Error trying to call create personal access token service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("Create executed successfully")
+	return c.JSON(http.StatusCreated, personalAccessToken)
+}
+
+func (p *personalAccessTokenHandler) GetAll(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "GetAll"),
+		slog.String("handler", "personalAccessToken"))
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	personalAccessTokens, err := p.personalAccessTokenService.GetAll(principal.Id)
+	if err != nil {
+		log.Error("Error trying to call get all personal access tokens service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("GetAll executed successfully")
+	return c.JSON(http.StatusOK, personalAccessTokens)
+}
+
+func (p *personalAccessTokenHandler) Revoke(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "Revoke"),
+		slog.String("handler", "personalAccessToken"))
+
+	id := c.Param("id")
+
+	if err := util.IsValidUUID(id); err != nil {
+		log.Warn("Invalid id")
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	err = p.personalAccessTokenService.Revoke(principal.Id, id)
+
+	if err != nil && errors.Is(err, model.ErrPersonalAccessTokenNotFound) {
+		log.Warn("Personal access token not found to revoke")
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	if err != nil {
+		log.Error("Error trying to call revoke personal access token service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("Revoke executed successfully")
+	return c.NoContent(http.StatusNoContent)
+}
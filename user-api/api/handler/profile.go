@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/labstack/echo/v4"
+)
+
+type profileHandler struct {
+	profileService model.ProfileService
+}
+
+func NewProfileHandler(profileService model.ProfileService) model.ProfileHandler {
+	return &profileHandler{
+		profileService: profileService,
+	}
+}
+
+func (p *profileHandler) GetByUserId(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "GetByUserId"),
+		slog.String("handler", "profile"))
+
+	id := c.Param("id")
+	if err := util.IsValidUUID(id); err != nil {
+		log.Warn("Invalid params")
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	profileResponse, err := p.profileService.GetByUserId(id)
+
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		log.Warn("User not found to get profile")
+		return c.JSON(http.StatusNotFound, err.Error())
+	}
+
+	if err != nil {
+		log.Error("Error trying to call get profile service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("Profile successfully rescued")
+	return c.JSON(http.StatusOK, profileResponse)
+}
+
+func (p *profileHandler) Update(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "Update"),
+		slog.String("handler", "profile"))
+
+	id := c.Param("id")
+	if err := util.IsValidUUID(id); err != nil {
+		log.Warn("Invalid params")
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	if id != principal.Id && !(principal.HasRole(model.RoleAdmin) && principal.HasScope(model.ScopeAdmin)) {
+		log.Warn("you cannot update the profile of a user other than yourself")
+		return c.NoContent(http.StatusForbidden)
+	}
+
+	var profilePayLoad model.ProfilePayLoad
+	if err := c.Bind(&profilePayLoad); err != nil {
+		log.Warn("Failed to bind profile data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := profilePayLoad.Validate(); err != nil {
+		log.Warn("Invalid profile data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	err = p.profileService.Update(id, profilePayLoad)
+
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		log.Warn("User not found to update profile")
+		return c.JSON(http.StatusNotFound, err.Error())
+	}
+
+	if err != nil {
+		log.Error("Error trying to call update profile service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("Profile successfully updated")
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/labstack/echo/v4"
+)
+
+type sessionHandler struct {
+	sessionService model.SessionService
+}
+
+func NewSessionHandler(sessionService model.SessionService) model.SessionHandler {
+	return &sessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+func (s *sessionHandler) GetAll(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "GetAll"),
+		slog.String("handler", "session"))
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	sessions, err := s.sessionService.GetAll(principal.Id, principal.SessionId)
+	if err != nil {
+		log.Error("Error trying to call get all sessions service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("GetAll executed successfully")
+	return c.JSON(http.StatusOK, sessions)
+}
+
+func (s *sessionHandler) Revoke(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "Revoke"),
+		slog.String("handler", "session"))
+
+	id := c.Param("id")
+
+	if err := util.IsValidUUID(id); err != nil {
+		log.Warn("Invalid id")
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	err = s.sessionService.Revoke(principal.Id, id)
+
+	if err != nil && errors.Is(err, model.ErrSessionNotFound) {
+		log.Warn("Session not found to revoke")
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	if err != nil {
+		log.Error("Error trying to call revoke session service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("Revoke executed successfully")
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *sessionHandler) RevokeOthers(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "RevokeOthers"),
+		slog.String("handler", "session"))
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	if err := s.sessionService.RevokeOthers(principal.Id, principal.SessionId); err != nil {
+		log.Error("Error trying to call revoke other sessions service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("RevokeOthers executed successfully")
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/labstack/echo/v4"
+)
+
+type totpHandler struct {
+	totpService model.TOTPService
+}
+
+func NewTOTPHandler(totpService model.TOTPService) model.TOTPHandler {
+	return &totpHandler{
+		totpService: totpService,
+	}
+}
+
+func (t *totpHandler) Enroll(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "Enroll"),
+		slog.String("handler", "totp"))
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	enrollment, err := t.totpService.Enroll(principal.Id)
+
+	if err != nil && errors.Is(err, model.ErrTOTPAlreadyEnabled) {
+		log.Warn("Two-factor authentication already enabled")
+		return c.JSON(http.StatusConflict, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		log.Warn("User not found to enroll totp")
+		return c.JSON(http.StatusNotFound, err)
+	}
+
+	if err != nil {
+		log.Error("Error trying to call totp enroll service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("Enroll executed successfully")
+	return c.JSON(http.StatusOK, enrollment)
+}
+
+func (t *totpHandler) Confirm(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "Confirm"),
+		slog.String("handler", "totp"))
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	var totpCode model.TOTPCode
+	if err := c.Bind(&totpCode); err != nil {
+		log.Warn("Failed to bind totpCode data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := totpCode.Validate(); err != nil {
+		log.Warn("Invalid totpCode data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	recoveryCodes, err := t.totpService.Confirm(principal.Id, totpCode.Code)
+
+	if err != nil && errors.Is(err, model.ErrInvalidTOTP) {
+		log.Warn("Wrong totp code")
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	if err != nil && errors.Is(err, model.ErrTOTPNotEnrolled) {
+		log.Warn("Totp not enrolled")
+		return c.JSON(http.StatusNotFound, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrTOTPAlreadyEnabled) {
+		log.Warn("Two-factor authentication already enabled")
+		return c.JSON(http.StatusConflict, err.Error())
+	}
+
+	if err != nil {
+		log.Error("Error trying to call totp confirm service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("Confirm executed successfully")
+	return c.JSON(http.StatusOK, recoveryCodes)
+}
+
+func (t *totpHandler) Disable(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "Disable"),
+		slog.String("handler", "totp"))
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	var totpCode model.TOTPCode
+	if err := c.Bind(&totpCode); err != nil {
+		log.Warn("Failed to bind totpCode data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := totpCode.Validate(); err != nil {
+		log.Warn("Invalid totpCode data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	err = t.totpService.Disable(principal.Id, totpCode.Code)
+
+	if err != nil && errors.Is(err, model.ErrInvalidTOTP) {
+		log.Warn("Wrong totp code")
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	if err != nil && errors.Is(err, model.ErrTOTPNotEnabled) {
+		log.Warn("Two-factor authentication not enabled")
+		return c.JSON(http.StatusNotFound, err.Error())
+	}
+
+	if err != nil {
+		log.Error("Error trying to call totp disable service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("Disable executed successfully")
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (t *totpHandler) Verify(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "Verify"),
+		slog.String("handler", "totp"))
+
+	var mfaVerify model.MFAVerify
+	if err := c.Bind(&mfaVerify); err != nil {
+		log.Warn("Failed to bind mfaVerify data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := mfaVerify.Validate(); err != nil {
+		log.Warn("Invalid mfaVerify data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	tokenPair, err := t.totpService.Verify(mfaVerify, util.ExtractClientInfo(c))
+
+	var loginThrottledError *model.LoginThrottledError
+	if err != nil && errors.As(err, &loginThrottledError) {
+		log.Warn("Too many failed login attempts")
+		retryAfter := int(math.Ceil(loginThrottledError.RetryAfter.Seconds()))
+		c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		return c.JSON(http.StatusTooManyRequests, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrMFAAttemptsExceeded) {
+		log.Warn("Too many wrong second factor attempts")
+		return c.JSON(http.StatusTooManyRequests, err.Error())
+	}
+
+	if err != nil && (errors.Is(err, model.ErrInvalidMFAToken) ||
+		errors.Is(err, model.ErrInvalidTOTP) ||
+		errors.Is(err, model.ErrTOTPNotEnabled) ||
+		errors.Is(err, model.ErrUserNotFound)) {
+		log.Warn("Second factor rejected")
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	if err != nil {
+		log.Error("Error trying to call totp verify service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("Verify executed successfully")
+	return c.JSON(http.StatusOK, tokenPair)
+}
@@ -41,11 +41,21 @@ func (uh userHandler) Create(c echo.Context) error {
 
 	err := uh.userService.Create(userPayLoad)
 
+	if err != nil && errors.Is(err, model.ErrEmailDomainNotAllowed) {
+		log.Warn("Email domain not allowed")
+		return c.JSON(http.StatusUnprocessableEntity, err.Error())
+	}
+
 	if err != nil && errors.Is(err, model.ErrUserAlreadyRegistered) {
 		log.Warn("There is already a registered user with this email: " + userPayLoad.Email)
 		return c.JSON(http.StatusConflict, err)
 	}
 
+	if err != nil && errors.Is(err, model.ErrMatriculaAlreadyRegistered) {
+		log.Warn("There is already a registered user with this matricula")
+		return c.JSON(http.StatusConflict, err.Error())
+	}
+
 	if err != nil {
 		log.Error("Error trying to call Create user service.")
 		return c.JSON(http.StatusInternalServerError, err)
@@ -177,13 +187,13 @@ func (uh userHandler) Update(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, err)
 	}
 
-	idFromToken, err := util.ExtractUserIdFromToken(c)
+	principal, err := util.GetPrincipal(c)
 	if err != nil {
-		log.Warn("err to get user if from token")
+		log.Warn("err to get principal from context")
 		return c.JSON(http.StatusUnauthorized, err)
 	}
 
-	if id != idFromToken {
+	if id != principal.Id && !(principal.HasRole(model.RoleAdmin) && principal.HasScope(model.ScopeAdmin)) {
 		log.Warn("you cannot update the data of a user other than yourself")
 		return c.NoContent(http.StatusForbidden)
 	}
@@ -194,16 +204,14 @@ func (uh userHandler) Update(c echo.Context) error {
 		return c.JSON(http.StatusUnprocessableEntity, err)
 	}
 
-	if userUpdatePayLoad.Email == "" && userUpdatePayLoad.Name == "" {
-		log.Warn("Both name and email are empty")
-		return c.JSON(http.StatusBadRequest, "Both name and email cannot be empty")
+	if userUpdatePayLoad.Email == "" && userUpdatePayLoad.Name == "" && userUpdatePayLoad.Matricula == "" {
+		log.Warn("Name, email and matricula are empty")
+		return c.JSON(http.StatusBadRequest, "Name, email and matricula cannot all be empty")
 	}
 
-	if userUpdatePayLoad.Name != "" {
-		if err := userUpdatePayLoad.Validate(); err != nil {
-			log.Warn("Invalid user data")
-			return c.JSON(http.StatusUnprocessableEntity, err)
-		}
+	if err := userUpdatePayLoad.Validate(); err != nil {
+		log.Warn("Invalid user data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
 	}
 
 	if userUpdatePayLoad.Email != "" {
@@ -225,12 +233,36 @@ func (uh userHandler) Update(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, err)
 	}
 
+	if err != nil && errors.Is(err, model.ErrEmailDomainNotAllowed) {
+		log.Warn("Email domain not allowed")
+		return c.JSON(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrUserAlreadyRegistered) {
+		log.Warn("There is already a registered user with this email: " + userUpdatePayLoad.Email)
+		return c.JSON(http.StatusConflict, err)
+	}
+
+	if err != nil && (errors.Is(err, model.ErrEmailChangeAttemptsExceeded) || errors.Is(err, model.ErrResendCooldown)) {
+		log.Warn("Too many email change requests")
+		return c.JSON(http.StatusTooManyRequests, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrMatriculaAlreadyRegistered) {
+		log.Warn("There is already a registered user with this matricula")
+		return c.JSON(http.StatusConflict, err.Error())
+	}
+
 	if err != nil {
 		log.Error("Error trying to call update user service.")
 		return c.JSON(http.StatusInternalServerError, err)
 	}
 
 	log.Info("Update executed successfully")
+	if userUpdatePayLoad.Email != "" {
+		return c.NoContent(http.StatusAccepted)
+	}
+
 	return c.NoContent(http.StatusNoContent)
 }
 
@@ -245,13 +277,13 @@ func (uh userHandler) Delete(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, err)
 	}
 
-	idFromToken, err := util.ExtractUserIdFromToken(c)
+	principal, err := util.GetPrincipal(c)
 	if err != nil {
-		log.Warn("err to get user if from token")
+		log.Warn("err to get principal from context")
 		return c.JSON(http.StatusUnauthorized, err)
 	}
 
-	if id != idFromToken {
+	if id != principal.Id && !(principal.HasRole(model.RoleAdmin) && principal.HasScope(model.ScopeAdmin)) {
 		log.Warn("you cannot delete the data of a user other than yourself")
 		return c.NoContent(http.StatusForbidden)
 	}
@@ -272,3 +304,158 @@ func (uh userHandler) Delete(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+func (uh userHandler) UpdateRole(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "updateRole"),
+		slog.String("handler", "user"))
+
+	id := c.Param("id")
+	if err := util.IsValidUUID(id); err != nil {
+		log.Warn("Invalid params")
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	var userRolePayLoad model.UserRolePayLoad
+	if err := c.Bind(&userRolePayLoad); err != nil {
+		log.Warn("Failed to bind user role data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := userRolePayLoad.Validate(); err != nil {
+		log.Warn("Invalid user role data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	err = uh.userService.UpdateRole(principal.Id, id, userRolePayLoad.Role)
+
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		log.Warn("User not found to update role")
+		return c.JSON(http.StatusNotFound, err)
+	}
+
+	if err != nil && errors.Is(err, model.ErrInvalidRole) {
+		log.Warn("Invalid role")
+		return c.JSON(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err != nil && (errors.Is(err, model.ErrChangeOwnRole) || errors.Is(err, model.ErrLastAdmin)) {
+		log.Warn("Role change refused: " + err.Error())
+		return c.JSON(http.StatusConflict, err.Error())
+	}
+
+	if err != nil {
+		log.Error("Error trying to call update role service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("User role successfully updated")
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (uh userHandler) ConfirmEmailChange(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "ConfirmEmailChange"),
+		slog.String("handler", "user"))
+
+	id := c.Param("id")
+	if err := util.IsValidUUID(id); err != nil {
+		log.Warn("Invalid params")
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	if id != principal.Id && !(principal.HasRole(model.RoleAdmin) && principal.HasScope(model.ScopeAdmin)) {
+		log.Warn("you cannot confirm the email change of a user other than yourself")
+		return c.NoContent(http.StatusForbidden)
+	}
+
+	var emailChangeConfirm model.EmailChangeConfirm
+	if err := c.Bind(&emailChangeConfirm); err != nil {
+		log.Warn("Failed to bind emailChangeConfirm data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := emailChangeConfirm.Validate(); err != nil {
+		log.Warn("Invalid emailChangeConfirm data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	err = uh.userService.ConfirmEmailChange(id, emailChangeConfirm.Code)
+
+	if err != nil && errors.Is(err, model.ErrEmailChangeNotFound) {
+		log.Warn("No pending email change to confirm")
+		return c.JSON(http.StatusNotFound, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrInvalidEmailChangeCode) {
+		log.Warn("Invalid email change code")
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrEmailChangeAttemptsExceeded) {
+		log.Warn("Email change attempts exceeded")
+		return c.JSON(http.StatusTooManyRequests, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrEmailDomainNotAllowed) {
+		log.Warn("Email domain not allowed")
+		return c.JSON(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrUserAlreadyRegistered) {
+		log.Warn("New email was registered by another user")
+		return c.JSON(http.StatusConflict, err.Error())
+	}
+
+	if err != nil {
+		log.Error("Error trying to call confirm email change service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("ConfirmEmailChange executed successfully")
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (uh userHandler) CancelEmailChange(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "CancelEmailChange"),
+		slog.String("handler", "user"))
+
+	var emailChangeCancel model.EmailChangeCancel
+	if err := c.Bind(&emailChangeCancel); err != nil {
+		log.Warn("Failed to bind emailChangeCancel data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := emailChangeCancel.Validate(); err != nil {
+		log.Warn("Invalid emailChangeCancel data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	err := uh.userService.CancelEmailChange(emailChangeCancel.Token)
+
+	if err != nil && errors.Is(err, model.ErrEmailChangeNotFound) {
+		log.Warn("No pending email change to cancel")
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	if err != nil {
+		log.Error("Error trying to call cancel email change service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("CancelEmailChange executed successfully")
+	return c.NoContent(http.StatusNoContent)
+}
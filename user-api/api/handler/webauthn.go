@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/labstack/echo/v4"
+)
+
+type webAuthnHandler struct {
+	webAuthnService model.WebAuthnService
+}
+
+func NewWebAuthnHandler(webAuthnService model.WebAuthnService) model.WebAuthnHandler {
+	return &webAuthnHandler{
+		webAuthnService: webAuthnService,
+	}
+}
+
+func (w *webAuthnHandler) BeginRegistration(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "BeginRegistration"),
+		slog.String("handler", "webAuthn"))
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	ceremony, err := w.webAuthnService.BeginRegistration(principal.Id)
+
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		log.Warn("User not found to register webauthn credential")
+		return c.JSON(http.StatusNotFound, err)
+	}
+
+	if err != nil {
+		log.Error("Error trying to call webauthn begin registration service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("BeginRegistration executed successfully")
+	return c.JSON(http.StatusOK, ceremony)
+}
+
+func (w *webAuthnHandler) FinishRegistration(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "FinishRegistration"),
+		slog.String("handler", "webAuthn"))
+
+	principal, err := util.GetPrincipal(c)
+	if err != nil {
+		log.Warn("err to get principal from context")
+		return c.JSON(http.StatusUnauthorized, err)
+	}
+
+	sessionId := c.QueryParam("sessionId")
+	if sessionId == "" {
+		log.Warn("empty entry of sessionId query params")
+		return c.String(http.StatusBadRequest, "The 'sessionId' parameter is required")
+	}
+
+	err = w.webAuthnService.FinishRegistration(principal.Id, sessionId, c.Request().Body)
+
+	if err != nil && (errors.Is(err, model.ErrWebAuthnSessionNotFound) || errors.Is(err, model.ErrWebAuthnRegistration)) {
+		log.Warn("Webauthn registration rejected: " + err.Error())
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if err != nil && errors.Is(err, model.ErrWebAuthnCredentialExists) {
+		log.Warn("Webauthn credential already registered")
+		return c.JSON(http.StatusConflict, err.Error())
+	}
+
+	if err != nil {
+		log.Error("Error trying to call webauthn finish registration service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("FinishRegistration executed successfully")
+	return c.NoContent(http.StatusCreated)
+}
+
+func (w *webAuthnHandler) BeginLogin(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "BeginLogin"),
+		slog.String("handler", "webAuthn"))
+
+	var webAuthnLogin model.WebAuthnLogin
+	if err := c.Bind(&webAuthnLogin); err != nil {
+		log.Warn("Failed to bind webAuthnLogin data to model")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := webAuthnLogin.Validate(); err != nil {
+		log.Warn("Invalid webAuthnLogin data")
+		return c.JSON(http.StatusUnprocessableEntity, err)
+	}
+
+	ceremony, err := w.webAuthnService.BeginLogin(webAuthnLogin.Email)
+
+	if err != nil && errors.Is(err, model.ErrWebAuthnLogin) {
+		log.Warn("Webauthn login not available")
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	if err != nil {
+		log.Error("Error trying to call webauthn begin login service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("BeginLogin executed successfully")
+	return c.JSON(http.StatusOK, ceremony)
+}
+
+func (w *webAuthnHandler) FinishLogin(c echo.Context) error {
+	log := slog.With(
+		slog.String("func", "FinishLogin"),
+		slog.String("handler", "webAuthn"))
+
+	sessionId := c.QueryParam("sessionId")
+	if sessionId == "" {
+		log.Warn("empty entry of sessionId query params")
+		return c.String(http.StatusBadRequest, "The 'sessionId' parameter is required")
+	}
+
+	tokenPair, err := w.webAuthnService.FinishLogin(sessionId, c.Request().Body, util.ExtractClientInfo(c))
+
+	if err != nil && (errors.Is(err, model.ErrWebAuthnSessionNotFound) ||
+		errors.Is(err, model.ErrWebAuthnLogin) ||
+		errors.Is(err, model.ErrWebAuthnCloneWarning) ||
+		errors.Is(err, model.ErrUserNotFound)) {
+		log.Warn("Webauthn login rejected: " + err.Error())
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	if err != nil {
+		log.Error("Error trying to call webauthn finish login service.")
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+
+	log.Info("FinishLogin executed successfully")
+	return c.JSON(http.StatusOK, tokenPair)
+}
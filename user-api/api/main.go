@@ -1,12 +1,24 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
 	"github.com/OVillas/user-api/api/handler"
 	"github.com/OVillas/user-api/config"
+	"github.com/OVillas/user-api/config/database"
 	"github.com/OVillas/user-api/middleware"
+	"github.com/OVillas/user-api/model"
 	"github.com/OVillas/user-api/repository"
 	"github.com/OVillas/user-api/service"
+	"github.com/OVillas/user-api/util"
 	"github.com/labstack/echo/v4"
 	Middleware "github.com/labstack/echo/v4/middleware"
 )
@@ -14,42 +26,255 @@ import (
 func main() {
 	config.Load()
 	e := echo.New()
+	e.IPExtractor = newIPExtractor()
+
+	db, err := database.NewMysqlConnection()
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	if err := util.LoadSigningKeys(config.JWTKeysDir, config.JWTSigningKeyID); err != nil {
+		e.Logger.Fatal(err)
+	}
 
 	e.Use(Middleware.CORSWithConfig(Middleware.CORSConfig{
-		AllowOrigins: []string{config.FrontendURL},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept},
+		AllowOrigins:  []string{config.FrontendURL},
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept},
+		ExposeHeaders: []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", echo.HeaderRetryAfter},
+		// The google login state lives in a cookie, so the front end must be
+		// allowed to send credentials.
+		AllowCredentials: true,
 	}))
-	configureUserRoutes(e)
-	configureAuthenticationRoutes(e)
-	e.Logger.Fatal(e.Start(fmt.Sprintf(":%d", config.Port)))
-}
+	e.Use(middleware.NewRateLimiterMiddleware(config.GlobalRateLimit))
 
-func configureUserRoutes(e *echo.Echo) {
-	userRepository := repository.NewUserRepository()
-	userService := service.NewUserService(userRepository)
+	userRepository := repository.NewUserRepository(db)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(db)
+	sessionRepository := repository.NewSessionRepository(db)
+	personalAccessTokenRepository := repository.NewPersonalAccessTokenRepository(db)
+	tokenRevocationStore := repository.NewRevokedTokenRepository(db)
+	confirmationCodeRepository := repository.NewConfirmationCodeRepository(db)
+	passwordResetCodeRepository := repository.NewPasswordResetCodeRepository(db)
+	resendThrottleRepository := repository.NewResendThrottleRepository(db)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(db, time.Hour)
+	totpRepository := repository.NewTOTPRepository(db)
+	loginHistoryRepository := repository.NewLoginHistoryRepository(db)
+	profileRepository := repository.NewProfileRepository(db)
+	blobStore := repository.NewLocalBlobStore(config.BlobStoreDir, config.BlobStoreBaseURL)
+	emailChangeRepository := repository.NewEmailChangeRepository(db)
+	webAuthnCredentialRepository := repository.NewWebAuthnCredentialRepository(db)
+	emailDomainPolicy := service.NewEmailDomainPolicy(config.EmailDomainRules, config.EmailDomainPolicyMode)
+	enrollmentVerifier, err := newEnrollmentVerifier()
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
 	emailService := service.NewEmailService("cineZuka", config.EmailSender, config.EMailSenderPassword)
-	authenticationService := service.NewAuthenticationService(userRepository, emailService)
+	userService := service.NewUserService(userRepository, sessionRepository, refreshTokenRepository, emailChangeRepository, emailService, emailDomainPolicy, enrollmentVerifier, blobStore)
+	loginHistoryService := service.NewLoginHistoryService(loginHistoryRepository, emailService)
+	profileService := service.NewProfileService(userRepository, profileRepository)
+	avatarService := service.NewAvatarService(userRepository, blobStore)
+	sessionService := service.NewSessionService(sessionRepository, refreshTokenRepository)
+	personalAccessTokenService := service.NewPersonalAccessTokenService(userRepository, personalAccessTokenRepository)
+	authenticationService := service.NewAuthenticationService(
+		userRepository,
+		refreshTokenRepository,
+		sessionRepository,
+		tokenRevocationStore,
+		confirmationCodeRepository,
+		passwordResetCodeRepository,
+		resendThrottleRepository,
+		loginAttemptRepository,
+		loginHistoryService,
+		totpRepository,
+		emailService,
+		emailDomainPolicy,
+		enrollmentVerifier,
+	)
+	oidcProvider := service.NewOIDCProvider(
+		config.GoogleIssuer,
+		config.GoogleClientID,
+		config.GoogleClientSecret,
+		config.GoogleRedirectURL,
+		config.GoogleJWKSURL,
+	)
+	googleAuthenticationService := service.NewGoogleAuthenticationService(userRepository, authenticationService, oidcProvider, emailDomainPolicy)
+	totpService := service.NewTOTPService(userRepository, totpRepository, tokenRevocationStore, loginAttemptRepository, authenticationService, loginHistoryService)
+	webAuthnService, err := service.NewWebAuthnService(
+		config.WebAuthnRPID,
+		config.WebAuthnRPOrigins,
+		userRepository,
+		webAuthnCredentialRepository,
+		authenticationService,
+	)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	authorizationMiddleware := middleware.NewAuthorizationMiddleware(tokenRevocationStore, sessionRepository, personalAccessTokenService)
+
+	e.Static("/uploads", config.BlobStoreDir)
+
+	configureKeyRoutes(e)
+	configureUserRoutes(e, userService, authenticationService, loginHistoryService, profileService, avatarService, authorizationMiddleware)
+	configureAuthenticationRoutes(
+		e,
+		authenticationService,
+		sessionService,
+		personalAccessTokenService,
+		googleAuthenticationService,
+		totpService,
+		webAuthnService,
+		authorizationMiddleware,
+	)
+
+	go func() {
+		if err := e.Start(fmt.Sprintf(":%d", config.Port)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.Logger.Fatal(err)
+		}
+	}()
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			if err := util.LoadSigningKeys(config.JWTKeysDir, config.JWTSigningKeyID); err != nil {
+				e.Logger.Error(err)
+			}
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := e.Shutdown(ctx); err != nil {
+		e.Logger.Error(err)
+	}
+
+	if err := database.Close(db); err != nil {
+		e.Logger.Error(err)
+	}
+}
+
+func newEnrollmentVerifier() (model.EnrollmentVerifier, error) {
+	switch config.EnrollmentVerifier {
+	case model.EnrollmentVerifierCSV:
+		return service.NewCSVEnrollmentVerifier(config.EnrollmentCSVPath)
+	case model.EnrollmentVerifierFake:
+		// ENROLLMENT_FAKE_MATRICULAS entries are "matricula:email".
+		records := []model.EnrollmentRecord{}
+		for _, entry := range config.EnrollmentFakeList {
+			matricula, email, _ := strings.Cut(strings.TrimSpace(entry), ":")
+			records = append(records, model.EnrollmentRecord{Matricula: matricula, Email: email})
+		}
+
+		return service.NewFakeEnrollmentVerifier(records...), nil
+	default:
+		return service.NewDisabledEnrollmentVerifier(), nil
+	}
+}
+
+// newIPExtractor only honours X-Forwarded-For when the request comes from one
+// of the configured proxies; otherwise the client could pick its own IP and
+// dodge the rate limiter and the login lockout.
+func newIPExtractor() echo.IPExtractor {
+	if len(config.TrustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range config.TrustedProxies {
+		options = append(options, echo.TrustIPRange(proxy))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+func configureKeyRoutes(e *echo.Echo) {
+	keyHandler := handler.NewKeyHandler()
+
+	e.GET("/.well-known/jwks.json", keyHandler.JWKS)
+}
+
+func configureUserRoutes(
+	e *echo.Echo,
+	userService model.UserService,
+	authenticationService model.AuthenticationService,
+	loginHistoryService model.LoginHistoryService,
+	profileService model.ProfileService,
+	avatarService model.AvatarService,
+	authorizationMiddleware model.AuthorizationMiddleware,
+) {
 	userHandler := handler.NewUserHandler(userService, authenticationService)
+	loginHistoryHandler := handler.NewLoginHistoryHandler(loginHistoryService)
+	profileHandler := handler.NewProfileHandler(profileService)
+	avatarHandler := handler.NewAvatarHandler(avatarService)
 
-	group := e.Group("v1/user")
+	group := e.Group("v1/user", middleware.NewRateLimiterMiddleware(config.UserRateLimit))
 	group.POST("", userHandler.Create)
 	group.GET("", userHandler.GetAll)
 	group.GET("/:id", userHandler.GetById)
 	group.GET("/name", userHandler.GetByName)
 	group.GET("/email", userHandler.GetByEmail)
-	group.PUT("/:id", userHandler.Update, middleware.CheckLoggedIn)
-	group.DELETE("/:id", userHandler.Delete, middleware.CheckLoggedIn)
+	group.PUT("/:id", userHandler.Update, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite))
+	group.DELETE("/:id", userHandler.Delete, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite))
+	group.POST("/email/cancel", userHandler.CancelEmailChange)
+	group.POST("/:id/email/confirm", userHandler.ConfirmEmailChange, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite))
+	group.GET("/:id/profile", profileHandler.GetByUserId)
+	group.PUT("/:id/profile", profileHandler.Update, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite))
+	group.PUT("/:id/avatar", avatarHandler.Upload, Middleware.BodyLimit("6M"), authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite))
+	group.GET("/:id/logins", loginHistoryHandler.GetByUserId, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserRead))
+	group.PATCH("/:id/role", userHandler.UpdateRole, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireRole(model.RoleAdmin), authorizationMiddleware.RequireScope(model.ScopeAdmin))
 }
 
-func configureAuthenticationRoutes(e *echo.Echo) {
-	userRepository := repository.NewUserRepository()
-	emailService := service.NewEmailService("cineZuka", config.EmailSender, config.EMailSenderPassword)
-	authenticationService := service.NewAuthenticationService(userRepository, emailService)
+func configureAuthenticationRoutes(
+	e *echo.Echo,
+	authenticationService model.AuthenticationService,
+	sessionService model.SessionService,
+	personalAccessTokenService model.PersonalAccessTokenService,
+	googleAuthenticationService model.GoogleAuthenticationService,
+	totpService model.TOTPService,
+	webAuthnService model.WebAuthnService,
+	authorizationMiddleware model.AuthorizationMiddleware,
+) {
 	authenticationHandler := handler.NewAuthenticationHandler(authenticationService)
+	sessionHandler := handler.NewSessionHandler(sessionService)
+	personalAccessTokenHandler := handler.NewPersonalAccessTokenHandler(personalAccessTokenService)
+	googleAuthenticationHandler := handler.NewGoogleAuthenticationHandler(googleAuthenticationService)
+	totpHandler := handler.NewTOTPHandler(totpService)
+	webAuthnHandler := handler.NewWebAuthnHandler(webAuthnService)
 
-	group := e.Group("v1/authentication")
+	group := e.Group("v1/authentication", middleware.NewRateLimiterMiddleware(config.AuthRateLimit))
 	group.POST("/login", authenticationHandler.Login)
-	group.PATCH("/user/:userId/password", authenticationHandler.UpdatePassword, middleware.CheckLoggedIn)
+	group.POST("/refresh", authenticationHandler.Refresh)
+	group.POST("/logout", authenticationHandler.Logout, authorizationMiddleware.CheckLoggedIn)
+	group.PATCH("/user/:userId/password", authenticationHandler.UpdatePassword, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite))
 	group.PATCH("/ConfirmEmail", authenticationHandler.ConfirmEmail)
+	group.POST("/confirm-email/resend", authenticationHandler.ResendConfirmationEmail)
+	group.POST("/password/forgot", authenticationHandler.ForgotPassword)
+	group.POST("/password/reset", authenticationHandler.ResetPassword)
+	group.POST("/magic-link", authenticationHandler.SendMagicLink)
+	group.POST("/magic-link/verify", authenticationHandler.MagicLinkLogin)
+	group.GET("/sessions", sessionHandler.GetAll, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserRead))
+	group.DELETE("/sessions", sessionHandler.RevokeOthers, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite))
+	group.DELETE("/sessions/:id", sessionHandler.Revoke, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite))
+	group.POST("/tokens", personalAccessTokenHandler.Create, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite), authorizationMiddleware.RequireInteractiveSession)
+	group.GET("/tokens", personalAccessTokenHandler.GetAll, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserRead))
+	group.DELETE("/tokens/:id", personalAccessTokenHandler.Revoke, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite))
+	group.GET("/google", googleAuthenticationHandler.Authorize)
+	group.POST("/google/callback", googleAuthenticationHandler.Callback)
+	group.POST("/2fa/totp", totpHandler.Enroll, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite), authorizationMiddleware.RequireInteractiveSession)
+	group.POST("/2fa/totp/confirm", totpHandler.Confirm, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite), authorizationMiddleware.RequireInteractiveSession)
+	group.DELETE("/2fa/totp", totpHandler.Disable, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite), authorizationMiddleware.RequireInteractiveSession)
+	group.POST("/2fa/verify", totpHandler.Verify)
+	group.POST("/webauthn/register/begin", webAuthnHandler.BeginRegistration, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite), authorizationMiddleware.RequireInteractiveSession)
+	group.POST("/webauthn/register/finish", webAuthnHandler.FinishRegistration, authorizationMiddleware.CheckLoggedIn, authorizationMiddleware.RequireScope(model.ScopeUserWrite), authorizationMiddleware.RequireInteractiveSession)
+	group.POST("/webauthn/login/begin", webAuthnHandler.BeginLogin)
+	group.POST("/webauthn/login/finish", webAuthnHandler.FinishLogin)
 
 }
@@ -3,21 +3,61 @@ package config
 import (
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+type EmailDomainRule struct {
+	Domain      string
+	Affiliation string
+}
+
 var (
 	Port                  = 0
 	MysqlConnectionString = ""
-	SecretKey             []byte
+	DBMaxOpenConns        = 0
+	DBMaxIdleConns        = 0
+	DBConnMaxLifetime     time.Duration
+	JWTKeysDir            = ""
+	JWTSigningKeyID       = ""
+	JWTIssuer             = ""
+	JWTAudience           = ""
+	AccessTokenTTL        time.Duration
+	RefreshTokenTTL       time.Duration
+	SessionMaxLifetime    time.Duration
 	FrontendURL           = ""
 	EmailSender           = ""
 	SMTPPort              = 0
 	SMTPServer            = ""
 	EMailSenderPassword   = ""
+	GoogleClientID        = ""
+	GoogleClientSecret    = ""
+	GoogleRedirectURL     = ""
+	GoogleIssuer          = ""
+	GoogleJWKSURL         = ""
+	WebAuthnRPID          = ""
+	WebAuthnRPOrigins     []string
+	EmailDomainRules      []EmailDomainRule
+	EmailDomainPolicyMode = ""
+	EnrollmentVerifier    = ""
+	EnrollmentCSVPath     = ""
+	EnrollmentFakeList    []string
+	BlobStoreDir          = ""
+	BlobStoreBaseURL      = ""
+	GlobalRateLimit       RateLimit
+	UserRateLimit         RateLimit
+	AuthRateLimit         RateLimit
+	TrustedProxies        []*net.IPNet
 )
 
 func Load() {
@@ -38,7 +78,52 @@ func Load() {
 		os.Getenv("DB_NAME"),
 	)
 
-	SecretKey = []byte(os.Getenv("SECRET_KEY"))
+	DBMaxOpenConns, err = strconv.Atoi(os.Getenv("DB_MAX_OPEN_CONNS"))
+	if err != nil {
+		DBMaxOpenConns = 25
+	}
+
+	DBMaxIdleConns, err = strconv.Atoi(os.Getenv("DB_MAX_IDLE_CONNS"))
+	if err != nil {
+		DBMaxIdleConns = 25
+	}
+
+	DBConnMaxLifetime, err = time.ParseDuration(os.Getenv("DB_CONN_MAX_LIFETIME"))
+	if err != nil {
+		DBConnMaxLifetime = 5 * time.Minute
+	}
+
+	JWTKeysDir = os.Getenv("JWT_KEYS_DIR")
+	if JWTKeysDir == "" {
+		JWTKeysDir = "keys"
+	}
+	JWTSigningKeyID = os.Getenv("JWT_SIGNING_KEY_ID")
+
+	JWTIssuer = os.Getenv("JWT_ISSUER")
+	if JWTIssuer == "" {
+		JWTIssuer = "conectauerj-user-api"
+	}
+
+	JWTAudience = os.Getenv("JWT_AUDIENCE")
+	if JWTAudience == "" {
+		JWTAudience = "conectauerj"
+	}
+
+	AccessTokenTTL, err = time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL"))
+	if err != nil {
+		AccessTokenTTL = 15 * time.Minute
+	}
+
+	RefreshTokenTTL, err = time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL"))
+	if err != nil {
+		RefreshTokenTTL = 7 * 24 * time.Hour
+	}
+
+	SessionMaxLifetime, err = time.ParseDuration(os.Getenv("SESSION_MAX_LIFETIME"))
+	if err != nil {
+		SessionMaxLifetime = 30 * 24 * time.Hour
+	}
+
 	FrontendURL = os.Getenv("FRONT_END_URL")
 
 	SMTPPort, err = strconv.Atoi(os.Getenv("PORT_MAIL"))
@@ -49,4 +134,122 @@ func Load() {
 	SMTPServer = os.Getenv("SMTP_SERVER")
 	EmailSender = os.Getenv("EMAIL_SENDER")
 	EMailSenderPassword = os.Getenv("EMAIL_SENDER_PASSWORD")
+
+	GoogleClientID = os.Getenv("GOOGLE_CLIENT_ID")
+	GoogleClientSecret = os.Getenv("GOOGLE_CLIENT_SECRET")
+	GoogleRedirectURL = os.Getenv("GOOGLE_REDIRECT_URL")
+	GoogleIssuer = os.Getenv("GOOGLE_ISSUER")
+	if GoogleIssuer == "" {
+		GoogleIssuer = "https://accounts.google.com"
+	}
+	GoogleJWKSURL = os.Getenv("GOOGLE_JWKS_URL")
+
+	WebAuthnRPID = os.Getenv("WEBAUTHN_RP_ID")
+	if WebAuthnRPID == "" {
+		WebAuthnRPID = "localhost"
+	}
+
+	WebAuthnRPOrigins = strings.Split(os.Getenv("WEBAUTHN_RP_ORIGINS"), ",")
+	if os.Getenv("WEBAUTHN_RP_ORIGINS") == "" {
+		WebAuthnRPOrigins = []string{FrontendURL}
+	}
+
+	EmailDomainRules = loadEmailDomainRules(os.Getenv("EMAIL_DOMAIN_RULES"))
+	EmailDomainPolicyMode = os.Getenv("EMAIL_DOMAIN_POLICY")
+	if EmailDomainPolicyMode == "" {
+		EmailDomainPolicyMode = "flag"
+	}
+
+	EnrollmentCSVPath = os.Getenv("ENROLLMENT_CSV_PATH")
+	EnrollmentVerifier = os.Getenv("ENROLLMENT_VERIFIER")
+	if EnrollmentVerifier == "" {
+		EnrollmentVerifier = "disabled"
+	}
+
+	// Until the registrar export is configured matriculas are stored without
+	// being verified. The fake verifier accepts whatever is listed in the
+	// environment, so it has to be asked for explicitly.
+	if EnrollmentVerifier != "disabled" && EnrollmentVerifier != "csv" && EnrollmentVerifier != "fake" {
+		log.Fatal("ENROLLMENT_VERIFIER must be disabled, csv or fake: " + EnrollmentVerifier)
+	}
+
+	if EnrollmentVerifier == "csv" && EnrollmentCSVPath == "" {
+		log.Fatal("ENROLLMENT_CSV_PATH is required by the csv enrollment verifier")
+	}
+
+	if os.Getenv("ENROLLMENT_FAKE_MATRICULAS") != "" {
+		EnrollmentFakeList = strings.Split(os.Getenv("ENROLLMENT_FAKE_MATRICULAS"), ",")
+	}
+
+	BlobStoreDir = os.Getenv("BLOB_STORE_DIR")
+	if BlobStoreDir == "" {
+		BlobStoreDir = "uploads"
+	}
+
+	BlobStoreBaseURL = os.Getenv("BLOB_STORE_BASE_URL")
+	if BlobStoreBaseURL == "" {
+		BlobStoreBaseURL = fmt.Sprintf("http://localhost:%d/uploads", Port)
+	}
+
+	GlobalRateLimit = loadRateLimit("RATE_LIMIT_GLOBAL", 20, 40)
+	UserRateLimit = loadRateLimit("RATE_LIMIT_USER", 5, 10)
+	AuthRateLimit = loadRateLimit("RATE_LIMIT_AUTHENTICATION", 1, 5)
+
+	TrustedProxies = loadTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+}
+
+func loadRateLimit(prefix string, defaultRequestsPerSecond float64, defaultBurst int) RateLimit {
+	requestsPerSecond, err := strconv.ParseFloat(os.Getenv(prefix+"_RPS"), 64)
+	if err != nil {
+		requestsPerSecond = defaultRequestsPerSecond
+	}
+
+	burst, err := strconv.Atoi(os.Getenv(prefix + "_BURST"))
+	if err != nil {
+		burst = defaultBurst
+	}
+
+	return RateLimit{
+		RequestsPerSecond: requestsPerSecond,
+		Burst:             burst,
+	}
+}
+
+func loadEmailDomainRules(value string) []EmailDomainRule {
+	if value == "" {
+		value = "graduacao.uerj.br:student,pos.uerj.br:student,uerj.br:staff"
+	}
+
+	rules := []EmailDomainRule{}
+	for _, rule := range strings.Split(value, ",") {
+		domain, affiliation, found := strings.Cut(strings.TrimSpace(rule), ":")
+		if !found || domain == "" || affiliation == "" {
+			continue
+		}
+
+		rules = append(rules, EmailDomainRule{
+			Domain:      strings.ToLower(domain),
+			Affiliation: affiliation,
+		})
+	}
+
+	return rules
+}
+
+func loadTrustedProxies(value string) []*net.IPNet {
+	proxies := []*net.IPNet{}
+	if value == "" {
+		return proxies
+	}
+
+	for _, cidr := range strings.Split(value, ",") {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		proxies = append(proxies, ipNet)
+	}
+
+	return proxies
 }
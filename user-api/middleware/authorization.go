@@ -1,40 +1,171 @@
 package middleware
 
 import (
-	"github.com/OVillas/user-api/config"
-	"github.com/golang-jwt/jwt"
-	"github.com/labstack/echo/v4"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/labstack/echo/v4"
 )
 
-func CheckLoggedIn(next echo.HandlerFunc) echo.HandlerFunc {
+const sessionTouchInterval = time.Minute
+
+type authorizationMiddleware struct {
+	tokenRevocationStore       model.TokenRevocationStore
+	sessionRepository          model.SessionRepository
+	personalAccessTokenService model.PersonalAccessTokenService
+}
+
+func NewAuthorizationMiddleware(
+	tokenRevocationStore model.TokenRevocationStore,
+	sessionRepository model.SessionRepository,
+	personalAccessTokenService model.PersonalAccessTokenService,
+) model.AuthorizationMiddleware {
+	return &authorizationMiddleware{
+		tokenRevocationStore:       tokenRevocationStore,
+		sessionRepository:          sessionRepository,
+		personalAccessTokenService: personalAccessTokenService,
+	}
+}
+
+func (am *authorizationMiddleware) CheckLoggedIn(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		log := slog.With(
+			slog.String("func", "CheckLoggedIn"),
+			slog.String("middleware", "authorization"))
+
+		tokenString := util.ExtractBearerToken(c)
+		if tokenString == "" {
+			return c.NoContent(http.StatusUnauthorized)
+		}
 
-		authorizationHeader := c.Request().Header.Get("Authorization")
+		if strings.HasPrefix(tokenString, util.PersonalTokenPrefix) {
+			principal, err := am.personalAccessTokenService.Authenticate(tokenString)
+			if err != nil && errors.Is(err, model.ErrInvalidPersonalAccessToken) {
+				return c.NoContent(http.StatusUnauthorized)
+			}
 
-		if authorizationHeader == "" {
+			if err != nil {
+				log.Error("Error: " + err.Error())
+				return c.NoContent(http.StatusInternalServerError)
+			}
+
+			util.SetPrincipal(c, principal)
+
+			return next(c)
+		}
+
+		principal, err := util.ParseToken(tokenString, util.TokenTypeAccess)
+		if err != nil {
 			return c.NoContent(http.StatusUnauthorized)
 		}
 
-		parts := strings.Split(authorizationHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		revoked, err := am.tokenRevocationStore.IsRevoked(principal.TokenId)
+		if err != nil {
+			log.Error("Error: " + err.Error())
+			return c.NoContent(http.StatusInternalServerError)
+		}
+
+		if revoked {
+			log.Warn("revoked token used: " + principal.TokenId)
 			return c.NoContent(http.StatusUnauthorized)
+		}
 
+		session, err := am.sessionRepository.GetById(principal.SessionId)
+		if err != nil {
+			log.Error("Error: " + err.Error())
+			return c.NoContent(http.StatusInternalServerError)
 		}
 
-		tokenString := parts[1]
+		if session == nil || !session.IsActive(time.Now()) || session.UserId != principal.Id {
+			log.Warn("token used for revoked or expired session: " + principal.SessionId)
+			return c.NoContent(http.StatusUnauthorized)
+		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(config.SecretKey), nil
-		})
+		if time.Since(session.LastSeenAt) > sessionTouchInterval {
+			if err := am.sessionRepository.Touch(session.Id, time.Now()); err != nil {
+				log.Warn("Error to update session last seen: " + err.Error())
+			}
+		}
+
+		util.SetPrincipal(c, principal)
+
+		return next(c)
+	}
+}
 
+func (am *authorizationMiddleware) RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			log := slog.With(
+				slog.String("func", "RequireRole"),
+				slog.String("middleware", "authorization"))
+
+			principal, err := util.GetPrincipal(c)
+			if err != nil {
+				log.Warn("err to get principal from context")
+				return c.NoContent(http.StatusUnauthorized)
+			}
+
+			for _, role := range roles {
+				if principal.HasRole(role) {
+					return next(c)
+				}
+			}
+
+			log.Warn("user does not have the required role")
+			return c.NoContent(http.StatusForbidden)
+		}
+	}
+}
+
+func (am *authorizationMiddleware) RequireScope(scopes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			log := slog.With(
+				slog.String("func", "RequireScope"),
+				slog.String("middleware", "authorization"))
+
+			principal, err := util.GetPrincipal(c)
+			if err != nil {
+				log.Warn("err to get principal from context")
+				return c.NoContent(http.StatusUnauthorized)
+			}
+
+			for _, scope := range scopes {
+				if !principal.HasScope(scope) {
+					log.Warn("token does not have the required scope: " + scope)
+					return c.NoContent(http.StatusForbidden)
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequireInteractiveSession keeps credential management out of reach of
+// personal access tokens, so a leaked token cannot mint new tokens or
+// replace the second factor of its owner.
+func (am *authorizationMiddleware) RequireInteractiveSession(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		log := slog.With(
+			slog.String("func", "RequireInteractiveSession"),
+			slog.String("middleware", "authorization"))
+
+		principal, err := util.GetPrincipal(c)
 		if err != nil {
+			log.Warn("err to get principal from context")
 			return c.NoContent(http.StatusUnauthorized)
 		}
 
-		if !token.Valid {
-			return c.NoContent(http.StatusUnauthorized)
+		if principal.TokenType != util.TokenTypeAccess {
+			log.Warn("interactive session required, got token type: " + principal.TokenType)
+			return c.NoContent(http.StatusForbidden)
 		}
 
 		return next(c)
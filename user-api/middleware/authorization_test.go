@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OVillas/user-api/config"
+	"github.com/OVillas/user-api/mocks"
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/repository"
+	"github.com/OVillas/user-api/util"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTokens(t *testing.T) {
+	t.Helper()
+
+	config.JWTIssuer = "conectauerj-user-api"
+	config.JWTAudience = "conectauerj"
+	config.AccessTokenTTL = 15 * time.Minute
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.pem"), data, 0o600))
+	require.NoError(t, util.LoadSigningKeys(dir, ""))
+}
+
+func serveWithToken(am model.AuthorizationMiddleware, token string) int {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	handler := am.CheckLoggedIn(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	if err := handler(c); err != nil {
+		e.HTTPErrorHandler(err, c)
+	}
+
+	return rec.Code
+}
+
+func TestCheckLoggedInRejectsReplayOfRevokedToken(t *testing.T) {
+	setupTokens(t)
+
+	user := model.User{Id: "6f1c2a8e-3b1d-4c5e-9f7a-2d4b6c8e0a12", Name: "Ana", Email: "ana@uerj.br"}
+	token, err := util.CreateToken(user, "9a7b5c3d-1e2f-4a6b-8c0d-e1f2a3b4c5d6", util.UserScopes(user))
+	require.NoError(t, err)
+
+	principal, err := util.ParseToken(token, util.TokenTypeAccess)
+	require.NoError(t, err)
+
+	sessionRepository := mocks.NewSessionRepository(t)
+	sessionRepository.On("GetById", "9a7b5c3d-1e2f-4a6b-8c0d-e1f2a3b4c5d6").Return(&model.Session{
+		Id:         "9a7b5c3d-1e2f-4a6b-8c0d-e1f2a3b4c5d6",
+		UserId:     user.Id,
+		LastSeenAt: time.Now(),
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}, nil).Once()
+
+	revocationStore := repository.NewInMemoryRevokedTokenRepository()
+	am := NewAuthorizationMiddleware(revocationStore, sessionRepository, mocks.NewPersonalAccessTokenService(t))
+
+	assert.Equal(t, http.StatusOK, serveWithToken(am, token))
+
+	revoked, err := revocationStore.Revoke(principal.TokenId, principal.ExpiresAt)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(am, token))
+}
+
+func TestCheckLoggedInRejectsTokenOfRevokedSession(t *testing.T) {
+	setupTokens(t)
+
+	user := model.User{Id: "6f1c2a8e-3b1d-4c5e-9f7a-2d4b6c8e0a12", Name: "Ana", Email: "ana@uerj.br"}
+	token, err := util.CreateToken(user, "9a7b5c3d-1e2f-4a6b-8c0d-e1f2a3b4c5d6", util.UserScopes(user))
+	require.NoError(t, err)
+
+	revokedAt := time.Now()
+	sessionRepository := mocks.NewSessionRepository(t)
+	sessionRepository.On("GetById", "9a7b5c3d-1e2f-4a6b-8c0d-e1f2a3b4c5d6").Return(&model.Session{
+		Id:        "9a7b5c3d-1e2f-4a6b-8c0d-e1f2a3b4c5d6",
+		UserId:    user.Id,
+		RevokedAt: &revokedAt,
+	}, nil).Once()
+
+	am := NewAuthorizationMiddleware(repository.NewInMemoryRevokedTokenRepository(), sessionRepository, mocks.NewPersonalAccessTokenService(t))
+
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(am, token))
+}
+
+func TestCheckLoggedInRejectsTokenOfExpiredSession(t *testing.T) {
+	setupTokens(t)
+
+	user := model.User{Id: "6f1c2a8e-3b1d-4c5e-9f7a-2d4b6c8e0a12", Name: "Ana", Email: "ana@uerj.br"}
+	token, err := util.CreateToken(user, "9a7b5c3d-1e2f-4a6b-8c0d-e1f2a3b4c5d6", util.UserScopes(user))
+	require.NoError(t, err)
+
+	sessionRepository := mocks.NewSessionRepository(t)
+	sessionRepository.On("GetById", "9a7b5c3d-1e2f-4a6b-8c0d-e1f2a3b4c5d6").Return(&model.Session{
+		Id:         "9a7b5c3d-1e2f-4a6b-8c0d-e1f2a3b4c5d6",
+		UserId:     user.Id,
+		LastSeenAt: time.Now(),
+		ExpiresAt:  time.Now().Add(-time.Minute),
+	}, nil).Once()
+
+	am := NewAuthorizationMiddleware(repository.NewInMemoryRevokedTokenRepository(), sessionRepository, mocks.NewPersonalAccessTokenService(t))
+
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(am, token))
+}
+
+func TestRequireInteractiveSessionRejectsPersonalAccessTokens(t *testing.T) {
+	am := NewAuthorizationMiddleware(repository.NewInMemoryRevokedTokenRepository(), mocks.NewSessionRepository(t), mocks.NewPersonalAccessTokenService(t))
+
+	handler := am.RequireInteractiveSession(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	testCases := []struct {
+		tokenType string
+		status    int
+	}{
+		{tokenType: util.TokenTypeAccess, status: http.StatusOK},
+		{tokenType: util.TokenTypePersonal, status: http.StatusForbidden},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.tokenType, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+			util.SetPrincipal(c, &model.Principal{Id: "6f1c2a8e-3b1d-4c5e-9f7a-2d4b6c8e0a12", TokenType: testCase.tokenType})
+
+			assert.NoError(t, handler(c))
+			assert.Equal(t, testCase.status, rec.Code)
+		})
+	}
+}
+
+func TestRequireRoleOnlyLetsAdminsThrough(t *testing.T) {
+	am := NewAuthorizationMiddleware(repository.NewInMemoryRevokedTokenRepository(), mocks.NewSessionRepository(t), mocks.NewPersonalAccessTokenService(t))
+
+	handler := am.RequireRole(model.RoleAdmin)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	testCases := []struct {
+		name   string
+		roles  []string
+		status int
+	}{
+		{name: "admin", roles: []string{model.RoleAdmin}, status: http.StatusOK},
+		{name: "user", roles: []string{model.RoleUser}, status: http.StatusForbidden},
+		{name: "no role", roles: nil, status: http.StatusForbidden},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodPatch, "/", nil), rec)
+			util.SetPrincipal(c, &model.Principal{Id: "6f1c2a8e-3b1d-4c5e-9f7a-2d4b6c8e0a12", Roles: testCase.roles})
+
+			assert.NoError(t, handler(c))
+			assert.Equal(t, testCase.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/OVillas/user-api/config"
+	"github.com/OVillas/user-api/util"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+)
+
+const (
+	rateLimitVisitorTTL = 10 * time.Minute
+	// rateLimitSharedIPFactor sizes the IP ceiling of signed-in requests
+	// against the per-user limit, to fit several users behind one address.
+	rateLimitSharedIPFactor = 10
+)
+
+type visitor struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type rateBucket struct {
+	limiter *rate.Limiter
+	factor  int
+}
+
+type rateLimiter struct {
+	limit       rate.Limit
+	burst       int
+	mutex       sync.Mutex
+	visitors    map[string]*visitor
+	lastCleanup time.Time
+}
+
+func NewRateLimiterMiddleware(rateLimit config.RateLimit) echo.MiddlewareFunc {
+	rl := &rateLimiter{
+		limit:       rate.Limit(rateLimit.RequestsPerSecond),
+		burst:       rateLimit.Burst,
+		visitors:    make(map[string]*visitor),
+		lastCleanup: time.Now(),
+	}
+
+	return rl.limitRequests
+}
+
+func (rl *rateLimiter) limitRequests(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Anonymous requests are limited per IP. Signed-in requests get their
+		// own bucket per user plus a looser ceiling for their IP, so a campus
+		// NAT does not pool the budget of its users and a user does not get
+		// more by spreading requests across addresses.
+		buckets := []rateBucket{{rl.getLimiter("ip:"+c.RealIP(), 1), 1}}
+		if userId := rateLimitUserId(c); userId != "" {
+			buckets = []rateBucket{
+				{rl.getLimiter("user:"+userId, 1), 1},
+				{rl.getLimiter("shared-ip:"+c.RealIP(), rateLimitSharedIPFactor), rateLimitSharedIPFactor},
+			}
+		}
+
+		var denied *rateBucket
+		for i := range buckets {
+			if !buckets[i].limiter.Allow() && denied == nil {
+				denied = &buckets[i]
+			}
+		}
+
+		tokens := buckets[0].limiter.Tokens()
+
+		header := c.Response().Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(rl.burst))
+		header.Set("RateLimit-Remaining", strconv.Itoa(int(math.Max(0, math.Floor(tokens)))))
+		header.Set("RateLimit-Reset", strconv.Itoa(rl.secondsUntil(float64(rl.burst)-tokens, 1)))
+
+		if denied != nil {
+			header.Set("Retry-After", strconv.Itoa(rl.secondsUntil(1-denied.limiter.Tokens(), denied.factor)))
+			return c.NoContent(http.StatusTooManyRequests)
+		}
+
+		return next(c)
+	}
+}
+
+func (rl *rateLimiter) getLimiter(key string, factor int) *rate.Limiter {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(rl.lastCleanup) > rateLimitVisitorTTL {
+		for visitorKey, v := range rl.visitors {
+			if now.Sub(v.lastSeen) > rateLimitVisitorTTL {
+				delete(rl.visitors, visitorKey)
+			}
+		}
+		rl.lastCleanup = now
+	}
+
+	v, ok := rl.visitors[key]
+	if !ok {
+		v = &visitor{limiter: rate.NewLimiter(rl.limit*rate.Limit(factor), rl.burst*factor)}
+		rl.visitors[key] = v
+	}
+
+	v.lastSeen = now
+	return v.limiter
+}
+
+func (rl *rateLimiter) secondsUntil(missingTokens float64, factor int) int {
+	if missingTokens <= 0 || rl.limit <= 0 {
+		return 0
+	}
+
+	return int(math.Ceil(missingTokens / (float64(rl.limit) * float64(factor))))
+}
+
+// rateLimitUserId runs before CheckLoggedIn, so it verifies the access token
+// itself; only a signed token picks the user bucket, a forged one just gets
+// the IP bucket. c.RealIP() relies on the echo IPExtractor set in main.
+func rateLimitUserId(c echo.Context) string {
+	tokenString := util.ExtractBearerToken(c)
+	if tokenString == "" {
+		return ""
+	}
+
+	principal, err := util.ParseToken(tokenString, util.TokenTypeAccess)
+	if err != nil {
+		return ""
+	}
+
+	return principal.Id
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OVillas/user-api/config"
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rateLimitedRequest(e *echo.Echo, remoteAddr string, forwardedFor string) int {
+	return rateLimitedRequestWithToken(e, remoteAddr, forwardedFor, "")
+}
+
+func rateLimitedRequestWithToken(e *echo.Echo, remoteAddr string, forwardedFor string, token string) int {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestRateLimiterIgnoresSpoofedForwardedFor(t *testing.T) {
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.Use(NewRateLimiterMiddleware(config.RateLimit{RequestsPerSecond: 0.001, Burst: 1}))
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	assert.Equal(t, http.StatusOK, rateLimitedRequest(e, "203.0.113.7:1234", "198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitedRequest(e, "203.0.113.7:1234", "198.51.100.2"))
+}
+
+func TestRateLimiterGivesUsersOnOneIPSeparateBudgets(t *testing.T) {
+	setupTokens(t)
+
+	ana, err := util.CreateToken(model.User{Id: "6f1c2a8e-3b1d-4c5e-9f7a-2d4b6c8e0a12", Role: model.RoleUser}, "ana-session", nil)
+	require.NoError(t, err)
+
+	bruno, err := util.CreateToken(model.User{Id: "0b7e5d3c-1a2f-4e6d-8c9b-7a5e3f1d2c4b", Role: model.RoleUser}, "bruno-session", nil)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.Use(NewRateLimiterMiddleware(config.RateLimit{RequestsPerSecond: 0.001, Burst: 1}))
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	assert.Equal(t, http.StatusOK, rateLimitedRequestWithToken(e, "203.0.113.7:1234", "", ana))
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitedRequestWithToken(e, "203.0.113.7:1234", "", ana))
+	assert.Equal(t, http.StatusOK, rateLimitedRequestWithToken(e, "203.0.113.7:1234", "", bruno))
+}
+
+func TestRateLimiterKeepsUserBudgetAcrossIPs(t *testing.T) {
+	setupTokens(t)
+
+	ana, err := util.CreateToken(model.User{Id: "6f1c2a8e-3b1d-4c5e-9f7a-2d4b6c8e0a12", Role: model.RoleUser}, "ana-session", nil)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.Use(NewRateLimiterMiddleware(config.RateLimit{RequestsPerSecond: 0.001, Burst: 1}))
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	assert.Equal(t, http.StatusOK, rateLimitedRequestWithToken(e, "203.0.113.7:1234", "", ana))
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitedRequestWithToken(e, "198.51.100.9:1234", "", ana))
+}
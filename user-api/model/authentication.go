@@ -9,21 +9,30 @@ import (
 )
 
 var (
-	ErrPasswordNotMatch        = errors.New("invalid password")
-	ErrGenToken                = errors.New("error to generate new token jwt")
-	ErrUnexpectedSigningMethod = errors.New("unexpected signature method")
-	ErrInvalidToken            = errors.New("token invalid")
-	ErrIdNotFoundInPermissions = errors.New("error to get id in token")
-	ErrIdIsNotAString          = errors.New("'id' field value is not a string")
-	ErrUpdatePassword          = errors.New("error to update password")
-	ErrToSendConfirmationCode  = errors.New("error to send confirmation code")
-	ErrInvalidOTP              = errors.New("Wrong or expired OTP")
-	ErrOTPNotFound             = errors.New("Not found OTP from email")
+	ErrPasswordNotMatch           = errors.New("invalid password")
+	ErrGenToken                   = errors.New("error to generate new token jwt")
+	ErrUnexpectedSigningMethod    = errors.New("unexpected signature method")
+	ErrInvalidToken               = errors.New("token invalid")
+	ErrIdNotFoundInPermissions    = errors.New("error to get id in token")
+	ErrIdIsNotAString             = errors.New("'id' field value is not a string")
+	ErrRolesNotFoundInPermissions = errors.New("error to get roles in token")
+	ErrUpdatePassword             = errors.New("error to update password")
+	ErrToSendConfirmationCode     = errors.New("error to send confirmation code")
+	ErrInvalidOTP                 = errors.New("Wrong or expired OTP")
+	ErrOTPNotFound                = errors.New("Not found OTP from email")
+	ErrOTPAttemptsExceeded        = errors.New("too many wrong attempts for this OTP")
+	ErrToSendPasswordResetCode    = errors.New("error to send password reset code")
+	ErrResendCooldown             = errors.New("wait before requesting a new code")
+	ErrResendDailyLimit           = errors.New("daily limit of requested codes reached")
+	ErrGenMagicLink               = errors.New("error to generate magic link")
+	ErrToSendMagicLink            = errors.New("error to send magic link")
+	ErrInvalidMagicLink           = errors.New("magic link invalid, expired or already used")
 )
 
 type Login struct {
-	Email    string `json:"email,omitempty" validate:"required,email"`
-	Password string `json:"password,omitempty" validate:"required"`
+	Email    string   `json:"email,omitempty" validate:"required,email"`
+	Password string   `json:"password,omitempty" validate:"required"`
+	Scopes   []string `json:"scopes,omitempty" validate:"omitempty,dive,oneof=user:read user:write admin"`
 }
 
 type UpdatePassword struct {
@@ -32,8 +41,47 @@ type UpdatePassword struct {
 }
 
 type ConfirmationCode struct {
-	Code       string
-	ExpiryTime time.Time
+	Email      string    `gorm:"column:Email"`
+	Code       string    `gorm:"column:Code"`
+	ExpiryTime time.Time `gorm:"column:ExpiryTime"`
+	Attempts   int       `gorm:"column:Attempts"`
+}
+
+type PasswordResetCode struct {
+	Email      string    `gorm:"column:Email"`
+	Code       string    `gorm:"column:Code"`
+	ExpiryTime time.Time `gorm:"column:ExpiryTime"`
+	Attempts   int       `gorm:"column:Attempts"`
+	LastSentAt time.Time `gorm:"column:LastSentAt"`
+}
+
+type ResendThrottle struct {
+	Key        string    `gorm:"column:Key"`
+	LastSentAt time.Time `gorm:"column:LastSentAt"`
+	WindowEnd  time.Time `gorm:"column:WindowEnd"`
+	Count      int       `gorm:"column:Count"`
+}
+
+type ResendConfirmationEmail struct {
+	Email string `json:"email,omitempty" validate:"required,email"`
+}
+
+type ForgotPassword struct {
+	Email string `json:"email,omitempty" validate:"required,email"`
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email,omitempty" validate:"required,email"`
+}
+
+type MagicLinkLogin struct {
+	Token string `json:"token,omitempty" validate:"required"`
+}
+
+type ResetPassword struct {
+	Email string `json:"email,omitempty" validate:"required,email"`
+	Code  string `json:"code,omitempty" validate:"required"`
+	New   string `json:"new,omitempty" validate:"required,min=6,containsany=!@#&?"`
 }
 
 type ConfirmCodeEmail struct {
@@ -41,6 +89,18 @@ type ConfirmCodeEmail struct {
 	Code  string `json:"code,omitempty" validate:"required"`
 }
 
+func (ConfirmationCode) TableName() string {
+	return "ConfirmationCodes"
+}
+
+func (PasswordResetCode) TableName() string {
+	return "PasswordResetCodes"
+}
+
+func (ResendThrottle) TableName() string {
+	return "ResendThrottles"
+}
+
 func (l *Login) Validate() error {
 	validate := validator.New()
 	return validate.Struct(l)
@@ -51,6 +111,31 @@ func (up *UpdatePassword) Validate() error {
 	return validate.Struct(up)
 }
 
+func (rc *ResendConfirmationEmail) Validate() error {
+	validate := validator.New()
+	return validate.Struct(rc)
+}
+
+func (fp *ForgotPassword) Validate() error {
+	validate := validator.New()
+	return validate.Struct(fp)
+}
+
+func (rp *ResetPassword) Validate() error {
+	validate := validator.New()
+	return validate.Struct(rp)
+}
+
+func (mr *MagicLinkRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(mr)
+}
+
+func (ml *MagicLinkLogin) Validate() error {
+	validate := validator.New()
+	return validate.Struct(ml)
+}
+
 func (ce *ConfirmCodeEmail) Validate() error {
 	validate := validator.New()
 	return validate.Struct(ce)
@@ -60,11 +145,56 @@ type AuthenticationHandler interface {
 	Login(c echo.Context) error
 	UpdatePassword(c echo.Context) error
 	ConfirmEmail(c echo.Context) error
+	ResendConfirmationEmail(c echo.Context) error
+	Refresh(c echo.Context) error
+	Logout(c echo.Context) error
+	ForgotPassword(c echo.Context) error
+	ResetPassword(c echo.Context) error
+	SendMagicLink(c echo.Context) error
+	MagicLinkLogin(c echo.Context) error
+}
+
+type ConfirmationCodeStore interface {
+	Save(code ConfirmationCode) error
+	Get(email string) (*ConfirmationCode, error)
+	IncrementAttempts(email string) (int, error)
+	Delete(email string) error
+}
+
+type PasswordResetCodeStore interface {
+	Save(code PasswordResetCode) error
+	Get(email string) (*PasswordResetCode, error)
+	IncrementAttempts(email string) (int, error)
+	Delete(email string) error
+}
+
+type ResendThrottleStore interface {
+	Save(throttle ResendThrottle) error
+	Get(key string) (*ResendThrottle, error)
+}
+
+type AuthorizationMiddleware interface {
+	CheckLoggedIn(next echo.HandlerFunc) echo.HandlerFunc
+	RequireRole(roles ...string) echo.MiddlewareFunc
+	RequireScope(scopes ...string) echo.MiddlewareFunc
+	RequireInteractiveSession(next echo.HandlerFunc) echo.HandlerFunc
 }
 
 type AuthenticationService interface {
-	Login(login Login) (string, error)
-	UpdatePassword(id string, updatePassword UpdatePassword) error
+	Login(login Login, client ClientInfo) (*TokenPair, error)
+	IssueTokens(user User, client ClientInfo, method string, scopes []string) (*TokenPair, error)
+	CompleteLogin(user User, client ClientInfo, method string, scopes []string) (*TokenPair, error)
+	CheckLoginThrottle(email string, ip string) error
+	RegisterLoginFailure(email string, ip string, user *User)
+	ResetLoginFailures(email string) error
+	Refresh(refreshToken string, scopes []string) (*TokenPair, error)
+	Logout(userId string, sessionId string, jti string, expiresAt time.Time, refreshToken string) error
+	UpdatePassword(id string, sessionId string, updatePassword UpdatePassword) error
 	SendConfirmationEmailCode(email string) error
 	ConfirmEmail(confirmCodeEmail ConfirmCodeEmail) error
+	ResendConfirmationEmailCode(email string) error
+	SendPasswordResetCode(email string) error
+	ResetPassword(resetPassword ResetPassword) error
+	SendMagicLink(email string) error
+	MagicLinkLogin(token string, client ClientInfo) (*TokenPair, error)
 }
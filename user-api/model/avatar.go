@@ -0,0 +1,30 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	ErrInvalidAvatar  = errors.New("avatar must be a jpeg, png or gif image")
+	ErrAvatarTooLarge = errors.New("avatar image is too large")
+	ErrSaveAvatar     = errors.New("error to save avatar")
+)
+
+const AvatarMaxSize = 5 << 20
+
+var AvatarSizes = []int{256, 128, 64}
+
+func AvatarKey(userId string, avatarId string, size int) string {
+	return fmt.Sprintf("avatars/%s/%s/%d.jpg", userId, avatarId, size)
+}
+
+type AvatarHandler interface {
+	Upload(c echo.Context) error
+}
+
+type AvatarService interface {
+	Upload(userId string, data []byte) (map[string]string, error)
+}
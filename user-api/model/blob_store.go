@@ -0,0 +1,7 @@
+package model
+
+type BlobStore interface {
+	Put(key string, contentType string, data []byte) error
+	Delete(key string) error
+	URL(key string) string
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrEmailChangeNotFound         = errors.New("no pending email change")
+	ErrInvalidEmailChangeCode      = errors.New("wrong or expired email change code")
+	ErrEmailChangeAttemptsExceeded = errors.New("too many wrong attempts for this email change")
+	ErrToSendEmailChange           = errors.New("error to send email change confirmation")
+	ErrUpdateEmail                 = errors.New("error to update email")
+)
+
+type EmailChangeRequest struct {
+	UserId          string    `gorm:"column:UserId"`
+	NewEmail        string    `gorm:"column:NewEmail"`
+	CodeHash        string    `gorm:"column:CodeHash"`
+	CancelTokenHash string    `gorm:"column:CancelTokenHash"`
+	Attempts        int       `gorm:"column:Attempts"`
+	ExpiresAt       time.Time `gorm:"column:ExpiresAt"`
+	CreatedAt       time.Time `gorm:"column:CreatedAt"`
+}
+
+func (EmailChangeRequest) TableName() string {
+	return "EmailChangeRequests"
+}
+
+type EmailChangeConfirm struct {
+	Code string `json:"code,omitempty" validate:"required"`
+}
+
+type EmailChangeCancel struct {
+	Token string `json:"token,omitempty" validate:"required"`
+}
+
+func (ec *EmailChangeConfirm) Validate() error {
+	validate := validator.New()
+	return validate.Struct(ec)
+}
+
+func (ec *EmailChangeCancel) Validate() error {
+	validate := validator.New()
+	return validate.Struct(ec)
+}
+
+type EmailChangeRepository interface {
+	Save(emailChangeRequest EmailChangeRequest) error
+	GetByUserId(userId string) (*EmailChangeRequest, error)
+	GetByCancelTokenHash(cancelTokenHash string) (*EmailChangeRequest, error)
+	IncrementAttempts(userId string) (int, error)
+	Delete(userId string) error
+}
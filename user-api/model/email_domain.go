@@ -0,0 +1,24 @@
+package model
+
+import "errors"
+
+// The default EMAIL_DOMAIN_RULES only map to student and staff, UERJ does not
+// give faculty a separate mail domain. AffiliationFaculty is only assigned
+// when a deployment configures a rule for it, e.g. "docente.uerj.br:faculty".
+const (
+	AffiliationStudent  = "student"
+	AffiliationStaff    = "staff"
+	AffiliationFaculty  = "faculty"
+	AffiliationExternal = "external"
+)
+
+const (
+	EmailDomainPolicyReject = "reject"
+	EmailDomainPolicyFlag   = "flag"
+)
+
+var ErrEmailDomainNotAllowed = errors.New("email domain is not allowed, use your institutional email")
+
+type EmailDomainPolicy interface {
+	Affiliation(email string) (string, error)
+}
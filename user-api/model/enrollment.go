@@ -0,0 +1,26 @@
+package model
+
+import "errors"
+
+var (
+	ErrMatriculaAlreadyRegistered = errors.New("there is already a registered user with this matricula")
+	ErrVerifyEnrollment           = errors.New("error to verify enrollment")
+)
+
+const (
+	EnrollmentVerifierDisabled = "disabled"
+	EnrollmentVerifierCSV      = "csv"
+	EnrollmentVerifierFake     = "fake"
+)
+
+type EnrollmentRecord struct {
+	Matricula string
+	Email     string
+}
+
+// EnrollmentVerifier only confirms a matricula when the registrar record has
+// the user's email, knowing a valid number is not enough. Callers pass an
+// email the user has already confirmed.
+type EnrollmentVerifier interface {
+	Verify(matricula string, email string) (bool, error)
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrLoginThrottled = errors.New("too many failed login attempts, try again later")
+)
+
+type LoginAttempt struct {
+	Key           string     `gorm:"column:Key"`
+	Failures      int        `gorm:"column:Failures"`
+	LastFailureAt time.Time  `gorm:"column:LastFailureAt"`
+	LockedUntil   *time.Time `gorm:"column:LockedUntil"`
+}
+
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+type LoginAttemptStore interface {
+	Get(key string) (*LoginAttempt, error)
+	RegisterFailure(key string) (*LoginAttempt, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
+
+func (LoginAttempt) TableName() string {
+	return "LoginAttempts"
+}
+
+func (e *LoginThrottledError) Error() string {
+	return ErrLoginThrottled.Error()
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrLoginThrottled
+}
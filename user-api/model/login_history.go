@@ -0,0 +1,57 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodGoogle    = "google"
+	LoginMethodTOTP      = "totp"
+	LoginMethodWebAuthn  = "webauthn"
+	LoginMethodMagicLink = "magic_link"
+)
+
+var ErrRecordLogin = errors.New("error to record login history")
+
+type LoginEvent struct {
+	Id                string    `gorm:"column:Id"`
+	UserId            string    `gorm:"column:UserId"`
+	Method            string    `gorm:"column:Method"`
+	Success           bool      `gorm:"column:Success"`
+	IP                string    `gorm:"column:IP"`
+	UserAgent         string    `gorm:"column:UserAgent"`
+	DeviceFingerprint string    `gorm:"column:DeviceFingerprint"`
+	CreatedAt         time.Time `gorm:"column:CreatedAt"`
+}
+
+func (LoginEvent) TableName() string {
+	return "LoginHistory"
+}
+
+type LoginEventResponse struct {
+	Method    string    `json:"method"`
+	Success   bool      `json:"success"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type LoginHistoryHandler interface {
+	GetByUserId(c echo.Context) error
+}
+
+type LoginHistoryService interface {
+	Record(user User, client ClientInfo, method string, success bool) error
+	GetByUserId(userId string) ([]LoginEventResponse, error)
+}
+
+type LoginHistoryRepository interface {
+	Create(loginEvent LoginEvent) error
+	GetByUserId(userId string) ([]LoginEvent, error)
+	HasSuccessfulLogin(userId string) (bool, error)
+	HasSuccessfulLoginFromDevice(userId string, deviceFingerprint string) (bool, error)
+}
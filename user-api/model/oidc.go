@@ -0,0 +1,67 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	ErrOIDCDiscovery        = errors.New("error to discover the identity provider configuration")
+	ErrOIDCInvalidState     = errors.New("invalid or expired oidc state")
+	ErrOIDCExchange         = errors.New("error to exchange authorization code")
+	ErrOIDCInvalidIDToken   = errors.New("invalid id token")
+	ErrOIDCEmailNotVerified = errors.New("email not verified by the identity provider")
+	ErrOIDCGenState         = errors.New("error to generate oidc state")
+	ErrOIDCAccountNotLinked = errors.New("an account with this email exists but its email is not confirmed")
+)
+
+const OIDCStateCookieName = "conecta_oidc_state"
+
+type OIDCState struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+	ExpiryTime   time.Time
+}
+
+type IDTokenClaims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type OIDCAuthorization struct {
+	AuthorizationURL string    `json:"authorizationUrl"`
+	StateToken       string    `json:"-"`
+	ExpiryTime       time.Time `json:"-"`
+}
+
+type OIDCCallback struct {
+	Code  string `json:"code,omitempty" validate:"required"`
+	State string `json:"state,omitempty" validate:"required"`
+}
+
+func (oc *OIDCCallback) Validate() error {
+	validate := validator.New()
+	return validate.Struct(oc)
+}
+
+type OIDCProvider interface {
+	AuthorizationURL(state string, nonce string, codeChallenge string) (string, error)
+	Exchange(code string, codeVerifier string) (string, error)
+	VerifyIDToken(rawIDToken string, nonce string) (*IDTokenClaims, error)
+}
+
+type GoogleAuthenticationHandler interface {
+	Authorize(c echo.Context) error
+	Callback(c echo.Context) error
+}
+
+type GoogleAuthenticationService interface {
+	Authorize() (*OIDCAuthorization, error)
+	Callback(oidcCallback OIDCCallback, stateToken string, client ClientInfo) (*TokenPair, error)
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	ScopeUserRead  = "user:read"
+	ScopeUserWrite = "user:write"
+	ScopeAdmin     = "admin"
+)
+
+var (
+	ErrPersonalAccessTokenNotFound = errors.New("personal access token not found")
+	ErrInvalidPersonalAccessToken  = errors.New("personal access token invalid or expired")
+	ErrGenPersonalAccessToken      = errors.New("error to generate personal access token")
+	ErrRevokePersonalAccessToken   = errors.New("error to revoke personal access token")
+	ErrScopeNotAllowed             = errors.New("scope not allowed for this user")
+)
+
+type PersonalAccessToken struct {
+	Id         string     `gorm:"column:Id"`
+	UserId     string     `gorm:"column:UserId"`
+	Name       string     `gorm:"column:Name"`
+	TokenHash  string     `gorm:"column:TokenHash"`
+	Scopes     string     `gorm:"column:Scopes"`
+	ExpiresAt  time.Time  `gorm:"column:ExpiresAt"`
+	LastUsedAt *time.Time `gorm:"column:LastUsedAt"`
+	RevokedAt  *time.Time `gorm:"column:RevokedAt"`
+	CreatedAt  time.Time  `gorm:"column:CreatedAt"`
+}
+
+func (PersonalAccessToken) TableName() string {
+	return "PersonalAccessTokens"
+}
+
+type PersonalAccessTokenPayLoad struct {
+	Name          string   `json:"name,omitempty" validate:"required,min=1,max=100"`
+	Scopes        []string `json:"scopes,omitempty" validate:"required,min=1,dive,oneof=user:read user:write admin"`
+	ExpiresInDays int      `json:"expiresInDays,omitempty" validate:"required,min=1,max=365"`
+}
+
+type PersonalAccessTokenResponse struct {
+	Id         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+type PersonalAccessTokenCreated struct {
+	PersonalAccessTokenResponse
+	Token string `json:"token"`
+}
+
+func (pp *PersonalAccessTokenPayLoad) Validate() error {
+	validate := validator.New()
+	return validate.Struct(pp)
+}
+
+type PersonalAccessTokenHandler interface {
+	Create(c echo.Context) error
+	GetAll(c echo.Context) error
+	Revoke(c echo.Context) error
+}
+
+type PersonalAccessTokenService interface {
+	Create(userId string, personalAccessTokenPayLoad PersonalAccessTokenPayLoad) (*PersonalAccessTokenCreated, error)
+	GetAll(userId string) ([]PersonalAccessTokenResponse, error)
+	Revoke(userId string, id string) error
+	Authenticate(token string) (*Principal, error)
+}
+
+type PersonalAccessTokenRepository interface {
+	Create(personalAccessToken PersonalAccessToken) error
+	GetById(id string) (*PersonalAccessToken, error)
+	GetByTokenHash(tokenHash string) (*PersonalAccessToken, error)
+	GetActiveByUserId(userId string) ([]PersonalAccessToken, error)
+	UpdateLastUsedAt(id string, lastUsedAt time.Time) error
+	Revoke(id string) error
+}
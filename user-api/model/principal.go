@@ -0,0 +1,40 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrPrincipalNotFound = errors.New("principal not found in request context")
+
+type Principal struct {
+	Id        string
+	Name      string
+	Email     string
+	Roles     []string
+	Scopes    []string
+	TokenId   string
+	TokenType string
+	SessionId string
+	ExpiresAt time.Time
+}
+
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
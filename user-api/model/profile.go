@@ -0,0 +1,107 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	ErrGetProfile  = errors.New("error to get profile")
+	ErrSaveProfile = errors.New("error to save profile")
+)
+
+type ProfileLink struct {
+	Label string `json:"label" validate:"required,max=30"`
+	URL   string `json:"url" validate:"required,http_url,max=255"`
+}
+
+type Profile struct {
+	UserId        string        `gorm:"column:UserId"`
+	Course        string        `gorm:"column:Course"`
+	Campus        string        `gorm:"column:Campus"`
+	EntryYear     int           `gorm:"column:EntryYear"`
+	EntrySemester int           `gorm:"column:EntrySemester"`
+	Bio           string        `gorm:"column:Bio"`
+	Pronouns      string        `gorm:"column:Pronouns"`
+	Links         []ProfileLink `gorm:"column:Links;serializer:json"`
+	LastModified  time.Time     `gorm:"column:LastModified"`
+}
+
+func (Profile) TableName() string {
+	return "Profiles"
+}
+
+type ProfilePayLoad struct {
+	Course        string        `json:"course,omitempty" validate:"max=100"`
+	Campus        string        `json:"campus,omitempty" validate:"max=100"`
+	EntryYear     int           `json:"entryYear,omitempty" validate:"required_with=EntrySemester,omitempty,min=1950,max=2100"`
+	EntrySemester int           `json:"entrySemester,omitempty" validate:"required_with=EntryYear,omitempty,oneof=1 2"`
+	Bio           string        `json:"bio,omitempty" validate:"max=280"`
+	Pronouns      string        `json:"pronouns,omitempty" validate:"max=30"`
+	Links         []ProfileLink `json:"links,omitempty" validate:"max=10,dive"`
+}
+
+type ProfileResponse struct {
+	UserId        string        `json:"userId"`
+	Course        string        `json:"course"`
+	Campus        string        `json:"campus"`
+	EntryYear     int           `json:"entryYear,omitempty"`
+	EntrySemester int           `json:"entrySemester,omitempty"`
+	Bio           string        `json:"bio"`
+	Pronouns      string        `json:"pronouns"`
+	Links         []ProfileLink `json:"links"`
+}
+
+func (pp *ProfilePayLoad) Validate() error {
+	validate := validator.New()
+	return validate.Struct(pp)
+}
+
+func (pp *ProfilePayLoad) ToProfile(userId string) *Profile {
+	return &Profile{
+		UserId:        userId,
+		Course:        pp.Course,
+		Campus:        pp.Campus,
+		EntryYear:     pp.EntryYear,
+		EntrySemester: pp.EntrySemester,
+		Bio:           pp.Bio,
+		Pronouns:      pp.Pronouns,
+		Links:         pp.Links,
+	}
+}
+
+func (p *Profile) ToProfileResponse() *ProfileResponse {
+	links := p.Links
+	if links == nil {
+		links = []ProfileLink{}
+	}
+
+	return &ProfileResponse{
+		UserId:        p.UserId,
+		Course:        p.Course,
+		Campus:        p.Campus,
+		EntryYear:     p.EntryYear,
+		EntrySemester: p.EntrySemester,
+		Bio:           p.Bio,
+		Pronouns:      p.Pronouns,
+		Links:         links,
+	}
+}
+
+type ProfileHandler interface {
+	GetByUserId(c echo.Context) error
+	Update(c echo.Context) error
+}
+
+type ProfileService interface {
+	GetByUserId(userId string) (*ProfileResponse, error)
+	Update(userId string, profilePayLoad ProfilePayLoad) error
+}
+
+type ProfileRepository interface {
+	GetByUserId(userId string) (*Profile, error)
+	Save(profile Profile) error
+}
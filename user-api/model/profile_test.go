@@ -0,0 +1,81 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfilePayLoadValidate(t *testing.T) {
+	tooManyLinks := make([]ProfileLink, 11)
+	for i := range tooManyLinks {
+		tooManyLinks[i] = ProfileLink{Label: "Site", URL: "https://ana.dev"}
+	}
+
+	testCases := []struct {
+		name    string
+		payLoad ProfilePayLoad
+		valid   bool
+	}{
+		{name: "empty profile", payLoad: ProfilePayLoad{}, valid: true},
+		{name: "entry year and semester", payLoad: ProfilePayLoad{EntryYear: 2023, EntrySemester: 2}, valid: true},
+		{name: "entry year without semester", payLoad: ProfilePayLoad{EntryYear: 2023}, valid: false},
+		{name: "entry semester without year", payLoad: ProfilePayLoad{EntrySemester: 1}, valid: false},
+		{name: "entry year too old", payLoad: ProfilePayLoad{EntryYear: 1949, EntrySemester: 1}, valid: false},
+		{name: "entry year too far ahead", payLoad: ProfilePayLoad{EntryYear: 2101, EntrySemester: 1}, valid: false},
+		{name: "third semester", payLoad: ProfilePayLoad{EntryYear: 2023, EntrySemester: 3}, valid: false},
+		{name: "bio too long", payLoad: ProfilePayLoad{Bio: strings.Repeat("a", 281)}, valid: false},
+		{
+			name:    "valid links",
+			payLoad: ProfilePayLoad{Links: []ProfileLink{{Label: "GitHub", URL: "https://github.com/ana"}, {Label: "Site", URL: "http://ana.dev"}}},
+			valid:   true,
+		},
+		{
+			name:    "link without label",
+			payLoad: ProfilePayLoad{Links: []ProfileLink{{URL: "https://github.com/ana"}}},
+			valid:   false,
+		},
+		{
+			name:    "link label too long",
+			payLoad: ProfilePayLoad{Links: []ProfileLink{{Label: strings.Repeat("a", 31), URL: "https://github.com/ana"}}},
+			valid:   false,
+		},
+		{
+			name:    "javascript url",
+			payLoad: ProfilePayLoad{Links: []ProfileLink{{Label: "Site", URL: "javascript:alert(1)"}}},
+			valid:   false,
+		},
+		{
+			name:    "ftp url",
+			payLoad: ProfilePayLoad{Links: []ProfileLink{{Label: "Site", URL: "ftp://ana.dev/cv.pdf"}}},
+			valid:   false,
+		},
+		{
+			name:    "url without scheme",
+			payLoad: ProfilePayLoad{Links: []ProfileLink{{Label: "Site", URL: "ana.dev"}}},
+			valid:   false,
+		},
+		{
+			name:    "invalid link after a valid one",
+			payLoad: ProfilePayLoad{Links: []ProfileLink{{Label: "GitHub", URL: "https://github.com/ana"}, {Label: "Site", URL: "not a url"}}},
+			valid:   false,
+		},
+		{
+			name:    "too many links",
+			payLoad: ProfilePayLoad{Links: tooManyLinks},
+			valid:   false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.payLoad.Validate()
+			if testCase.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrCreateSession   = errors.New("error to create session")
+	ErrRevokeSession   = errors.New("error to revoke session")
+)
+
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type Session struct {
+	Id         string     `gorm:"column:Id"`
+	UserId     string     `gorm:"column:UserId"`
+	UserAgent  string     `gorm:"column:UserAgent"`
+	IP         string     `gorm:"column:IP"`
+	Scopes     string     `gorm:"column:Scopes"`
+	CreatedAt  time.Time  `gorm:"column:CreatedAt"`
+	LastSeenAt time.Time  `gorm:"column:LastSeenAt"`
+	ExpiresAt  time.Time  `gorm:"column:ExpiresAt"`
+	RevokedAt  *time.Time `gorm:"column:RevokedAt"`
+}
+
+func (Session) TableName() string {
+	return "Sessions"
+}
+
+// IsActive reports whether the session was neither revoked nor outlived its
+// absolute lifetime, which refreshing tokens does not extend.
+func (s Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+type SessionResponse struct {
+	Id         string    `json:"id"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	Current    bool      `json:"current"`
+}
+
+type SessionHandler interface {
+	GetAll(c echo.Context) error
+	Revoke(c echo.Context) error
+	RevokeOthers(c echo.Context) error
+}
+
+type SessionService interface {
+	GetAll(userId string, currentSessionId string) ([]SessionResponse, error)
+	Revoke(userId string, sessionId string) error
+	RevokeOthers(userId string, currentSessionId string) error
+}
+
+type SessionRepository interface {
+	Create(session Session) error
+	GetById(id string) (*Session, error)
+	GetActiveByUserId(userId string) ([]Session, error)
+	Touch(id string, lastSeenAt time.Time) error
+	Revoke(id string) error
+	RevokeAllForUser(userId string, exceptId string) error
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	ErrNoSigningKeys          = errors.New("no signing keys found in key directory")
+	ErrSigningKeyNotFound     = errors.New("signing key not found")
+	ErrUnsupportedSigningKey  = errors.New("unsupported signing key type")
+	ErrSigningKeyIdNotInToken = errors.New("error to get kid in token header")
+)
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type KeyHandler interface {
+	JWKS(c echo.Context) error
+}
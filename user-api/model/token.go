@@ -0,0 +1,77 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token already used, token family revoked")
+	ErrGenRefreshToken     = errors.New("error to generate new refresh token")
+	ErrTokenIdNotFound     = errors.New("error to get jti in token")
+	ErrRevokeToken         = errors.New("error to revoke token")
+)
+
+type RefreshToken struct {
+	Id        string     `gorm:"column:Id"`
+	UserId    string     `gorm:"column:UserId"`
+	FamilyId  string     `gorm:"column:FamilyId"`
+	TokenHash string     `gorm:"column:TokenHash"`
+	ExpiresAt time.Time  `gorm:"column:ExpiresAt"`
+	UsedAt    *time.Time `gorm:"column:UsedAt"`
+	RevokedAt *time.Time `gorm:"column:RevokedAt"`
+	CreatedAt time.Time  `gorm:"column:CreatedAt"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"accessToken,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	ExpiresIn    int64  `json:"expiresIn,omitempty"`
+	MFARequired  bool   `json:"mfaRequired,omitempty"`
+	MFAToken     string `json:"mfaToken,omitempty"`
+}
+
+type Refresh struct {
+	RefreshToken string   `json:"refreshToken,omitempty" validate:"required"`
+	Scopes       []string `json:"scopes,omitempty" validate:"omitempty,dive,oneof=user:read user:write admin"`
+}
+
+type RevokedToken struct {
+	Jti       string    `gorm:"column:Jti"`
+	ExpiresAt time.Time `gorm:"column:ExpiresAt"`
+}
+
+type Logout struct {
+	RefreshToken string `json:"refreshToken,omitempty"`
+}
+
+// TokenRevocationStore.Revoke reports false when the jti was already
+// revoked, which lets single-use tokens be consumed atomically.
+type TokenRevocationStore interface {
+	Revoke(jti string, expiresAt time.Time) (bool, error)
+	IsRevoked(jti string) (bool, error)
+}
+
+type RefreshTokenRepository interface {
+	Create(refreshToken RefreshToken) error
+	GetByTokenHash(tokenHash string) (*RefreshToken, error)
+	MarkUsed(id string) (bool, error)
+	RevokeFamily(familyId string) error
+	RevokeAllForUser(userId string, exceptFamilyId string) error
+}
+
+func (r *Refresh) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+func (RefreshToken) TableName() string {
+	return "RefreshTokens"
+}
+
+func (RevokedToken) TableName() string {
+	return "RevokedTokens"
+}
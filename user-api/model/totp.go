@@ -0,0 +1,96 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	ErrTOTPAlreadyEnabled  = errors.New("two-factor authentication already enabled")
+	ErrTOTPNotEnrolled     = errors.New("two-factor authentication not enrolled")
+	ErrTOTPNotEnabled      = errors.New("two-factor authentication not enabled")
+	ErrInvalidTOTP         = errors.New("invalid two-factor authentication code")
+	ErrGenTOTPSecret       = errors.New("error to generate two-factor authentication secret")
+	ErrInvalidMFAToken     = errors.New("mfa token invalid or expired")
+	ErrGenMFAToken         = errors.New("error to generate mfa token")
+	ErrMFAAttemptsExceeded = errors.New("too many wrong second factor attempts, sign in again")
+)
+
+type UserTOTP struct {
+	UserId       string    `gorm:"column:UserId"`
+	Secret       string    `gorm:"column:Secret"`
+	Enabled      bool      `gorm:"column:Enabled"`
+	LastUsedStep int64     `gorm:"column:LastUsedStep"`
+	CreatedAt    time.Time `gorm:"column:CreatedAt"`
+}
+
+type RecoveryCode struct {
+	Id        string     `gorm:"column:Id"`
+	UserId    string     `gorm:"column:UserId"`
+	CodeHash  string     `gorm:"column:CodeHash"`
+	UsedAt    *time.Time `gorm:"column:UsedAt"`
+	CreatedAt time.Time  `gorm:"column:CreatedAt"`
+}
+
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type TOTPCode struct {
+	Code string `json:"code,omitempty" validate:"required"`
+}
+
+type MFAVerify struct {
+	MFAToken string `json:"mfaToken,omitempty" validate:"required"`
+	Code     string `json:"code,omitempty" validate:"required"`
+}
+
+func (UserTOTP) TableName() string {
+	return "UserTOTP"
+}
+
+func (RecoveryCode) TableName() string {
+	return "RecoveryCodes"
+}
+
+func (tc *TOTPCode) Validate() error {
+	validate := validator.New()
+	return validate.Struct(tc)
+}
+
+func (mv *MFAVerify) Validate() error {
+	validate := validator.New()
+	return validate.Struct(mv)
+}
+
+type TOTPHandler interface {
+	Enroll(c echo.Context) error
+	Confirm(c echo.Context) error
+	Disable(c echo.Context) error
+	Verify(c echo.Context) error
+}
+
+type TOTPService interface {
+	Enroll(userId string) (*TOTPEnrollment, error)
+	Confirm(userId string, code string) (*RecoveryCodes, error)
+	Disable(userId string, code string) error
+	Verify(mfaVerify MFAVerify, client ClientInfo) (*TokenPair, error)
+}
+
+type TOTPRepository interface {
+	Save(userTOTP UserTOTP) error
+	GetByUserId(userId string) (*UserTOTP, error)
+	Enable(userId string) error
+	UpdateLastUsedStep(userId string, step int64) (bool, error)
+	Delete(userId string) error
+	ReplaceRecoveryCodes(userId string, codeHashes []string) error
+	UseRecoveryCode(userId string, codeHash string) (bool, error)
+}
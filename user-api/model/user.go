@@ -19,33 +19,60 @@ var (
 	ErrUserNotFound             = errors.New("user not found")
 	ErrDeleteUser               = errors.New("error to delete user")
 	ErrSameEmail                = errors.New("the email cannot be the same as the previous one")
+	ErrUpdateRole               = errors.New("error to update user role")
+	ErrInvalidRole              = errors.New("the role is not valid")
+	ErrChangeOwnRole            = errors.New("you cannot change your own role")
+	ErrLastAdmin                = errors.New("the last admin cannot be demoted")
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+var Roles = []string{RoleUser, RoleAdmin}
+
 type User struct {
-	Id               string    `gorm:"column:Id"`
-	Name             string    `gorm:"column:Name"`
-	Email            string    `gorm:"column:Email"`
-	Password         string    `gorm:"column:Password"`
-	IsEmailConfirmed bool      `gorm:"column:IsEmailConfirmed"`
-	CreatedAt        time.Time `gorm:"column:CreatedAt"`
-	LastModified     time.Time `gorm:"column:LastModified"`
+	Id                string    `gorm:"column:Id"`
+	Name              string    `gorm:"column:Name"`
+	Email             string    `gorm:"column:Email"`
+	Password          string    `gorm:"column:Password"`
+	Role              string    `gorm:"column:Role"`
+	Affiliation       string    `gorm:"column:Affiliation"`
+	Matricula         *string   `gorm:"column:Matricula"`
+	MatriculaVerified bool      `gorm:"column:MatriculaVerified"`
+	AvatarId          *string   `gorm:"column:AvatarId"`
+	GoogleId          *string   `gorm:"column:GoogleId"`
+	IsEmailConfirmed  bool      `gorm:"column:IsEmailConfirmed"`
+	CreatedAt         time.Time `gorm:"column:CreatedAt"`
+	LastModified      time.Time `gorm:"column:LastModified"`
 }
 
 type UserPayLoad struct {
-	Name     string `json:"name,omitempty" validate:"required,min=1,max=75"`
-	Email    string `json:"email,omitempty" validate:"required,email"`
-	Password string `json:"password,omitempty" validate:"required,min=6,containsany=!@#&?"`
+	Name      string `json:"name,omitempty" validate:"required,min=1,max=75"`
+	Email     string `json:"email,omitempty" validate:"required,email"`
+	Password  string `json:"password,omitempty" validate:"required,min=6,containsany=!@#&?"`
+	Matricula string `json:"matricula,omitempty" validate:"omitempty,numeric,len=12"`
 }
 
 type UserUpdatePayLoad struct {
-	Name  string `json:"name,omitempty" validate:"min=1,max=75"`
-	Email string `json:"email,omitempty"`
+	Name      string `json:"name,omitempty" validate:"omitempty,min=1,max=75"`
+	Email     string `json:"email,omitempty"`
+	Matricula string `json:"matricula,omitempty" validate:"omitempty,numeric,len=12"`
+}
+
+type UserRolePayLoad struct {
+	Role string `json:"role,omitempty" validate:"required,oneof=user admin"`
 }
 
 type UserResponse struct {
 	Id               string
 	Name             string
 	Email            string
+	Role             string
+	Affiliation      string
+	VerifiedStudent  bool
+	AvatarURLs       map[string]string
 	IsEmailConfirmed bool
 	CreatedAt        string
 	LastModified     string
@@ -59,6 +86,9 @@ type UserHandler interface {
 	GetAll(c echo.Context) error
 	Update(c echo.Context) error
 	Delete(c echo.Context) error
+	UpdateRole(c echo.Context) error
+	ConfirmEmailChange(c echo.Context) error
+	CancelEmailChange(c echo.Context) error
 }
 
 type UserService interface {
@@ -69,6 +99,9 @@ type UserService interface {
 	GetAll() ([]UserResponse, error)
 	Update(id string, userUpdate UserUpdatePayLoad) error
 	Delete(id string) error
+	UpdateRole(principalId string, id string, role string) error
+	ConfirmEmailChange(id string, code string) error
+	CancelEmailChange(token string) error
 }
 
 type UserRepository interface {
@@ -76,11 +109,19 @@ type UserRepository interface {
 	GetById(id string) (*User, error)
 	GetByName(name string) ([]User, error)
 	GetByEmail(email string) (*User, error)
+	GetByMatricula(matricula string) (*User, error)
 	GetAll() ([]User, error)
 	Update(id string, user User) error
 	Delete(id string) error
 	UpdatePassword(id string, password string) error
-	UpdateConfirmedEmail(id string) error
+	UpdateConfirmedEmail(id string, affiliation string) error
+	UpdateMatriculaVerified(id string, verified bool) error
+	UpdateEmail(id string, email string, affiliation string) error
+	UpdateRole(id string, role string) error
+	CountByRole(role string) (int64, error)
+	UpdateAvatar(id string, avatarId string) error
+	GetByGoogleId(googleId string) (*User, error)
+	UpdateGoogleId(id string, googleId string) error
 }
 
 func (upl *UserPayLoad) Validate() error {
@@ -93,6 +134,11 @@ func (uu *UserUpdatePayLoad) Validate() error {
 	return validate.Struct(uu)
 }
 
+func (urp *UserRolePayLoad) Validate() error {
+	validate := validator.New()
+	return validate.Struct(urp)
+}
+
 func (upl *UserPayLoad) ToUser(hashedPassword string) (*User, error) {
 	id, err := uuid.NewRandom()
 	if err != nil {
@@ -104,6 +150,7 @@ func (upl *UserPayLoad) ToUser(hashedPassword string) (*User, error) {
 		Name:     upl.Name,
 		Email:    upl.Email,
 		Password: hashedPassword,
+		Role:     RoleUser,
 	}, nil
 }
 
@@ -119,6 +166,9 @@ func (u *User) ToUserResponse() *UserResponse {
 		Id:               u.Id,
 		Name:             u.Name,
 		Email:            u.Email,
+		Role:             u.Role,
+		Affiliation:      u.Affiliation,
+		VerifiedStudent:  u.Matricula != nil && u.MatriculaVerified,
 		IsEmailConfirmed: u.IsEmailConfirmed,
 		CreatedAt:        u.CreatedAt.Format("2006-01-02 15:04:05"),
 		LastModified:     u.LastModified.Format("2006-01-02 15:04:05"),
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	ErrWebAuthnSessionNotFound  = errors.New("webauthn session not found or expired")
+	ErrWebAuthnRegistration     = errors.New("webauthn registration failed")
+	ErrWebAuthnLogin            = errors.New("webauthn assertion failed")
+	ErrWebAuthnCloneWarning     = errors.New("webauthn sign count did not increase, authenticator may be cloned")
+	ErrWebAuthnCredentialExists = errors.New("webauthn credential already registered")
+)
+
+type WebAuthnCredential struct {
+	Id              string     `gorm:"column:Id"`
+	UserId          string     `gorm:"column:UserId"`
+	PublicKey       []byte     `gorm:"column:PublicKey"`
+	AttestationType string     `gorm:"column:AttestationType"`
+	Transport       string     `gorm:"column:Transport"`
+	AAGUID          []byte     `gorm:"column:AAGUID"`
+	SignCount       uint32     `gorm:"column:SignCount"`
+	CreatedAt       time.Time  `gorm:"column:CreatedAt"`
+	LastUsedAt      *time.Time `gorm:"column:LastUsedAt"`
+}
+
+type WebAuthnCeremony struct {
+	SessionId string      `json:"sessionId"`
+	Options   interface{} `json:"options"`
+}
+
+type WebAuthnLogin struct {
+	Email string `json:"email,omitempty" validate:"omitempty,email"`
+}
+
+func (WebAuthnCredential) TableName() string {
+	return "WebAuthnCredentials"
+}
+
+func (wl *WebAuthnLogin) Validate() error {
+	validate := validator.New()
+	return validate.Struct(wl)
+}
+
+type WebAuthnHandler interface {
+	BeginRegistration(c echo.Context) error
+	FinishRegistration(c echo.Context) error
+	BeginLogin(c echo.Context) error
+	FinishLogin(c echo.Context) error
+}
+
+type WebAuthnService interface {
+	BeginRegistration(userId string) (*WebAuthnCeremony, error)
+	FinishRegistration(userId string, sessionId string, body io.Reader) error
+	BeginLogin(email string) (*WebAuthnCeremony, error)
+	FinishLogin(sessionId string, body io.Reader, client ClientInfo) (*TokenPair, error)
+}
+
+type WebAuthnCredentialRepository interface {
+	Create(credential WebAuthnCredential) error
+	GetById(id string) (*WebAuthnCredential, error)
+	GetByUserId(userId string) ([]WebAuthnCredential, error)
+	UpdateSignCount(id string, signCount uint32) error
+}
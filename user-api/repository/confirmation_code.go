@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type confirmationCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewConfirmationCodeRepository(db *gorm.DB) model.ConfirmationCodeStore {
+	return confirmationCodeRepository{
+		db: db,
+	}
+}
+
+func (cr confirmationCodeRepository) Save(code model.ConfirmationCode) error {
+	log := slog.With(
+		slog.String("func", "Save"),
+		slog.String("repository", "confirmationCode"))
+
+	if err := cr.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&code).Error; err != nil {
+		log.Error("Error to save confirmation code in database: " + err.Error())
+		return err
+	}
+
+	if err := cr.db.Where("ExpiryTime < ?", time.Now()).Delete(&model.ConfirmationCode{}).Error; err != nil {
+		log.Warn("Error to purge expired confirmation codes: " + err.Error())
+	}
+
+	log.Info("save repository executed successfully")
+	return nil
+}
+
+func (cr confirmationCodeRepository) Get(email string) (*model.ConfirmationCode, error) {
+	log := slog.With(
+		slog.String("func", "Get"),
+		slog.String("repository", "confirmationCode"))
+
+	var code model.ConfirmationCode
+	err := cr.db.Where("Email = ?", email).First(&code).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &code, nil
+}
+
+func (cr confirmationCodeRepository) IncrementAttempts(email string) (int, error) {
+	log := slog.With(
+		slog.String("func", "IncrementAttempts"),
+		slog.String("repository", "confirmationCode"))
+
+	var code model.ConfirmationCode
+	err := cr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.ConfirmationCode{}).
+			Where("Email = ?", email).
+			Update("Attempts", gorm.Expr("Attempts + 1")).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("Email = ?", email).First(&code).Error
+	})
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return 0, err
+	}
+
+	log.Info("increment attempts repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+
+	return code.Attempts, nil
+}
+
+func (cr confirmationCodeRepository) Delete(email string) error {
+	log := slog.With(
+		slog.String("func", "Delete"),
+		slog.String("repository", "confirmationCode"))
+
+	if err := cr.db.Delete(&model.ConfirmationCode{}, "Email = ?", email).Error; err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("delete repository executed successfully")
+	return nil
+}
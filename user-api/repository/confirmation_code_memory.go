@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type inMemoryConfirmationCodeRepository struct {
+	mutex sync.Mutex
+	codes map[string]model.ConfirmationCode
+}
+
+func NewInMemoryConfirmationCodeRepository(ctx context.Context, cleanupInterval time.Duration) model.ConfirmationCodeStore {
+	ir := &inMemoryConfirmationCodeRepository{
+		codes: make(map[string]model.ConfirmationCode),
+	}
+
+	go ir.evictExpired(ctx, cleanupInterval)
+
+	return ir
+}
+
+func (ir *inMemoryConfirmationCodeRepository) Save(code model.ConfirmationCode) error {
+	ir.mutex.Lock()
+	defer ir.mutex.Unlock()
+
+	ir.codes[code.Email] = code
+	return nil
+}
+
+func (ir *inMemoryConfirmationCodeRepository) Get(email string) (*model.ConfirmationCode, error) {
+	ir.mutex.Lock()
+	defer ir.mutex.Unlock()
+
+	code, ok := ir.codes[email]
+	if !ok {
+		return nil, nil
+	}
+
+	return &code, nil
+}
+
+func (ir *inMemoryConfirmationCodeRepository) IncrementAttempts(email string) (int, error) {
+	ir.mutex.Lock()
+	defer ir.mutex.Unlock()
+
+	code, ok := ir.codes[email]
+	if !ok {
+		return 0, nil
+	}
+
+	code.Attempts++
+	ir.codes[email] = code
+	return code.Attempts, nil
+}
+
+func (ir *inMemoryConfirmationCodeRepository) Delete(email string) error {
+	ir.mutex.Lock()
+	defer ir.mutex.Unlock()
+
+	delete(ir.codes, email)
+	return nil
+}
+
+func (ir *inMemoryConfirmationCodeRepository) evictExpired(ctx context.Context, cleanupInterval time.Duration) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			ir.mutex.Lock()
+			for email, code := range ir.codes {
+				if now.After(code.ExpiryTime) {
+					delete(ir.codes, email)
+				}
+			}
+			ir.mutex.Unlock()
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryConfirmationCodeRepositoryEvictsExpiredCodes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := NewInMemoryConfirmationCodeRepository(ctx, 10*time.Millisecond)
+
+	require.NoError(t, store.Save(model.ConfirmationCode{Email: "expired@uerj.br", Code: "111111", ExpiryTime: time.Now()}))
+	require.NoError(t, store.Save(model.ConfirmationCode{Email: "valid@uerj.br", Code: "222222", ExpiryTime: time.Now().Add(time.Hour)}))
+
+	assert.Eventually(t, func() bool {
+		code, err := store.Get("expired@uerj.br")
+		return err == nil && code == nil
+	}, time.Second, 10*time.Millisecond)
+
+	code, err := store.Get("valid@uerj.br")
+	require.NoError(t, err)
+	require.NotNil(t, code)
+	assert.Equal(t, "222222", code.Code)
+}
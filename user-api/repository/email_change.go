@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type emailChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailChangeRepository(db *gorm.DB) model.EmailChangeRepository {
+	return emailChangeRepository{
+		db: db,
+	}
+}
+
+func (er emailChangeRepository) Save(emailChangeRequest model.EmailChangeRequest) error {
+	log := slog.With(
+		slog.String("func", "Save"),
+		slog.String("repository", "emailChange"))
+
+	emailChangeRequest.CreatedAt = time.Now()
+
+	if err := er.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&emailChangeRequest).Error; err != nil {
+		log.Error("Error to save email change request in database: " + err.Error())
+		return err
+	}
+
+	if err := er.db.Where("ExpiresAt < ?", time.Now()).Delete(&model.EmailChangeRequest{}).Error; err != nil {
+		log.Warn("Error to purge expired email change requests: " + err.Error())
+	}
+
+	log.Info("save repository executed successfully")
+	return nil
+}
+
+func (er emailChangeRepository) GetByUserId(userId string) (*model.EmailChangeRequest, error) {
+	log := slog.With(
+		slog.String("func", "GetByUserId"),
+		slog.String("repository", "emailChange"))
+
+	var emailChangeRequest model.EmailChangeRequest
+	err := er.db.Where("UserId = ?", userId).First(&emailChangeRequest).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get by user id repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &emailChangeRequest, nil
+}
+
+func (er emailChangeRepository) GetByCancelTokenHash(cancelTokenHash string) (*model.EmailChangeRequest, error) {
+	log := slog.With(
+		slog.String("func", "GetByCancelTokenHash"),
+		slog.String("repository", "emailChange"))
+
+	var emailChangeRequest model.EmailChangeRequest
+	err := er.db.Where("CancelTokenHash = ?", cancelTokenHash).First(&emailChangeRequest).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get by cancel token hash repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &emailChangeRequest, nil
+}
+
+func (er emailChangeRepository) IncrementAttempts(userId string) (int, error) {
+	log := slog.With(
+		slog.String("func", "IncrementAttempts"),
+		slog.String("repository", "emailChange"))
+
+	var emailChangeRequest model.EmailChangeRequest
+	err := er.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.EmailChangeRequest{}).
+			Where("UserId = ?", userId).
+			Update("Attempts", gorm.Expr("Attempts + 1")).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("UserId = ?", userId).First(&emailChangeRequest).Error
+	})
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return 0, err
+	}
+
+	log.Info("increment attempts repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+
+	return emailChangeRequest.Attempts, nil
+}
+
+func (er emailChangeRepository) Delete(userId string) error {
+	log := slog.With(
+		slog.String("func", "Delete"),
+		slog.String("repository", "emailChange"))
+
+	if err := er.db.Delete(&model.EmailChangeRequest{}, "UserId = ?", userId).Error; err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("delete repository executed successfully")
+	return nil
+}
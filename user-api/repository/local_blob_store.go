@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/OVillas/user-api/model"
+)
+
+var errInvalidBlobKey = errors.New("invalid blob key")
+
+type localBlobStore struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalBlobStore(dir string, baseURL string) model.BlobStore {
+	return localBlobStore{
+		dir:     dir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (lb localBlobStore) Put(key string, contentType string, data []byte) error {
+	log := slog.With(
+		slog.String("func", "Put"),
+		slog.String("repository", "localBlobStore"))
+
+	path, err := lb.path(key)
+	if err != nil {
+		log.Warn("Invalid blob key: " + key)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("put repository executed successfully")
+	return nil
+}
+
+func (lb localBlobStore) Delete(key string) error {
+	log := slog.With(
+		slog.String("func", "Delete"),
+		slog.String("repository", "localBlobStore"))
+
+	path, err := lb.path(key)
+	if err != nil {
+		log.Warn("Invalid blob key: " + key)
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("delete repository executed successfully")
+	return nil
+}
+
+func (lb localBlobStore) URL(key string) string {
+	return lb.baseURL + "/" + key
+}
+
+func (lb localBlobStore) path(key string) (string, error) {
+	path := filepath.Join(lb.dir, filepath.FromSlash(key))
+
+	rel, err := filepath.Rel(lb.dir, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", errInvalidBlobKey
+	}
+
+	return path, nil
+}
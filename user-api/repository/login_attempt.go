@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type loginAttemptRepository struct {
+	db            *gorm.DB
+	failureWindow time.Duration
+}
+
+func NewLoginAttemptRepository(db *gorm.DB, failureWindow time.Duration) model.LoginAttemptStore {
+	return loginAttemptRepository{
+		db:            db,
+		failureWindow: failureWindow,
+	}
+}
+
+func (lr loginAttemptRepository) Get(key string) (*model.LoginAttempt, error) {
+	log := slog.With(
+		slog.String("func", "Get"),
+		slog.String("repository", "loginAttempt"))
+
+	now := time.Now()
+
+	var attempt model.LoginAttempt
+	err := lr.db.Where("`Key` = ?", key).
+		Where("LastFailureAt >= ? OR LockedUntil >= ?", now.Add(-lr.failureWindow), now).
+		First(&attempt).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &attempt, nil
+}
+
+func (lr loginAttemptRepository) RegisterFailure(key string) (*model.LoginAttempt, error) {
+	log := slog.With(
+		slog.String("func", "RegisterFailure"),
+		slog.String("repository", "loginAttempt"))
+
+	now := time.Now()
+
+	// Failures restart from one when the previous ones are out of the window
+	// and no lock is running. MySQL applies the assignments in order, so
+	// Failures still sees the old LastFailureAt.
+	var attempt model.LoginAttempt
+	err := lr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Set{
+				{
+					Column: clause.Column{Name: "Failures"},
+					Value: gorm.Expr("IF(LastFailureAt < ? AND (LockedUntil IS NULL OR LockedUntil < ?), 1, Failures + 1)",
+						now.Add(-lr.failureWindow), now),
+				},
+				{Column: clause.Column{Name: "LastFailureAt"}, Value: now},
+			},
+		}).Create(&model.LoginAttempt{Key: key, Failures: 1, LastFailureAt: now}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("`Key` = ?", key).First(&attempt).Error
+	})
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	err = lr.db.Where("LastFailureAt < ? AND (LockedUntil IS NULL OR LockedUntil < ?)", now.Add(-lr.failureWindow), now).
+		Delete(&model.LoginAttempt{}).Error
+	if err != nil {
+		log.Warn("Error to purge stale login attempts: " + err.Error())
+	}
+
+	log.Info("register failure repository executed successfully")
+	return &attempt, nil
+}
+
+func (lr loginAttemptRepository) Lock(key string, until time.Time) error {
+	log := slog.With(
+		slog.String("func", "Lock"),
+		slog.String("repository", "loginAttempt"))
+
+	err := lr.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{"LockedUntil": until}),
+	}).Create(&model.LoginAttempt{Key: key, LastFailureAt: time.Now(), LockedUntil: &until}).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("lock repository executed successfully")
+	return nil
+}
+
+func (lr loginAttemptRepository) Reset(key string) error {
+	log := slog.With(
+		slog.String("func", "Reset"),
+		slog.String("repository", "loginAttempt"))
+
+	if err := lr.db.Delete(&model.LoginAttempt{}, "`Key` = ?", key).Error; err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("reset repository executed successfully")
+	return nil
+}
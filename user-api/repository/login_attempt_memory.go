@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type inMemoryLoginAttemptRepository struct {
+	mutex         sync.Mutex
+	failureWindow time.Duration
+	attempts      map[string]model.LoginAttempt
+}
+
+func NewInMemoryLoginAttemptRepository(failureWindow time.Duration) model.LoginAttemptStore {
+	return &inMemoryLoginAttemptRepository{
+		failureWindow: failureWindow,
+		attempts:      make(map[string]model.LoginAttempt),
+	}
+}
+
+func (ir *inMemoryLoginAttemptRepository) Get(key string) (*model.LoginAttempt, error) {
+	ir.mutex.Lock()
+	defer ir.mutex.Unlock()
+
+	attempt, ok := ir.attempts[key]
+	if !ok || ir.isStale(attempt, time.Now()) {
+		return nil, nil
+	}
+
+	return &attempt, nil
+}
+
+func (ir *inMemoryLoginAttemptRepository) RegisterFailure(key string) (*model.LoginAttempt, error) {
+	ir.mutex.Lock()
+	defer ir.mutex.Unlock()
+
+	now := time.Now()
+	ir.evictStale(now)
+
+	attempt, ok := ir.attempts[key]
+	if !ok {
+		attempt = model.LoginAttempt{Key: key}
+	}
+
+	attempt.Failures++
+	attempt.LastFailureAt = now
+	ir.attempts[key] = attempt
+
+	return &attempt, nil
+}
+
+func (ir *inMemoryLoginAttemptRepository) Lock(key string, until time.Time) error {
+	ir.mutex.Lock()
+	defer ir.mutex.Unlock()
+
+	attempt, ok := ir.attempts[key]
+	if !ok {
+		attempt = model.LoginAttempt{Key: key}
+	}
+
+	attempt.LockedUntil = &until
+	ir.attempts[key] = attempt
+
+	return nil
+}
+
+func (ir *inMemoryLoginAttemptRepository) Reset(key string) error {
+	ir.mutex.Lock()
+	defer ir.mutex.Unlock()
+
+	delete(ir.attempts, key)
+	return nil
+}
+
+func (ir *inMemoryLoginAttemptRepository) isStale(attempt model.LoginAttempt, now time.Time) bool {
+	return (attempt.LockedUntil == nil || now.After(*attempt.LockedUntil)) && now.Sub(attempt.LastFailureAt) > ir.failureWindow
+}
+
+func (ir *inMemoryLoginAttemptRepository) evictStale(now time.Time) {
+	for key, attempt := range ir.attempts {
+		if ir.isStale(attempt, now) {
+			delete(ir.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/OVillas/user-api/model"
+)
+
+const loginHistoryLimit = 100
+
+type loginHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginHistoryRepository(db *gorm.DB) model.LoginHistoryRepository {
+	return loginHistoryRepository{
+		db: db,
+	}
+}
+
+func (lr loginHistoryRepository) Create(loginEvent model.LoginEvent) error {
+	log := slog.With(
+		slog.String("func", "Create"),
+		slog.String("repository", "loginHistory"))
+
+	loginEvent.CreatedAt = time.Now()
+
+	if err := lr.db.Create(&loginEvent).Error; err != nil {
+		log.Error("Error to create login event in database: " + err.Error())
+		return err
+	}
+
+	log.Info("create repository executed successfully")
+	return nil
+}
+
+func (lr loginHistoryRepository) GetByUserId(userId string) ([]model.LoginEvent, error) {
+	log := slog.With(
+		slog.String("func", "GetByUserId"),
+		slog.String("repository", "loginHistory"))
+
+	var loginEvents []model.LoginEvent
+	err := lr.db.Where("UserId = ?", userId).
+		Order("CreatedAt DESC").
+		Limit(loginHistoryLimit).
+		Find(&loginEvents).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get by user id repository executed successfully")
+	return loginEvents, nil
+}
+
+func (lr loginHistoryRepository) HasSuccessfulLogin(userId string) (bool, error) {
+	log := slog.With(
+		slog.String("func", "HasSuccessfulLogin"),
+		slog.String("repository", "loginHistory"))
+
+	var count int64
+	err := lr.db.Model(&model.LoginEvent{}).
+		Where("UserId = ? AND Success = ?", userId, true).
+		Count(&count).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return false, err
+	}
+
+	log.Info("has successful login repository executed successfully")
+	return count > 0, nil
+}
+
+func (lr loginHistoryRepository) HasSuccessfulLoginFromDevice(userId string, deviceFingerprint string) (bool, error) {
+	log := slog.With(
+		slog.String("func", "HasSuccessfulLoginFromDevice"),
+		slog.String("repository", "loginHistory"))
+
+	var count int64
+	err := lr.db.Model(&model.LoginEvent{}).
+		Where("UserId = ? AND DeviceFingerprint = ? AND Success = ?", userId, deviceFingerprint, true).
+		Count(&count).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return false, err
+	}
+
+	log.Info("has successful login from device repository executed successfully")
+	return count > 0, nil
+}
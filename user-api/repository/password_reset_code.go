@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type passwordResetCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetCodeRepository(db *gorm.DB) model.PasswordResetCodeStore {
+	return passwordResetCodeRepository{
+		db: db,
+	}
+}
+
+func (pr passwordResetCodeRepository) Save(code model.PasswordResetCode) error {
+	log := slog.With(
+		slog.String("func", "Save"),
+		slog.String("repository", "passwordResetCode"))
+
+	if err := pr.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&code).Error; err != nil {
+		log.Error("Error to save password reset code in database: " + err.Error())
+		return err
+	}
+
+	if err := pr.db.Where("ExpiryTime < ?", time.Now()).Delete(&model.PasswordResetCode{}).Error; err != nil {
+		log.Warn("Error to purge expired password reset codes: " + err.Error())
+	}
+
+	log.Info("save repository executed successfully")
+	return nil
+}
+
+func (pr passwordResetCodeRepository) Get(email string) (*model.PasswordResetCode, error) {
+	log := slog.With(
+		slog.String("func", "Get"),
+		slog.String("repository", "passwordResetCode"))
+
+	var code model.PasswordResetCode
+	err := pr.db.Where("Email = ?", email).First(&code).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &code, nil
+}
+
+func (pr passwordResetCodeRepository) IncrementAttempts(email string) (int, error) {
+	log := slog.With(
+		slog.String("func", "IncrementAttempts"),
+		slog.String("repository", "passwordResetCode"))
+
+	var code model.PasswordResetCode
+	err := pr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.PasswordResetCode{}).
+			Where("Email = ?", email).
+			Update("Attempts", gorm.Expr("Attempts + 1")).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("Email = ?", email).First(&code).Error
+	})
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return 0, err
+	}
+
+	log.Info("increment attempts repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+
+	return code.Attempts, nil
+}
+
+func (pr passwordResetCodeRepository) Delete(email string) error {
+	log := slog.With(
+		slog.String("func", "Delete"),
+		slog.String("repository", "passwordResetCode"))
+
+	if err := pr.db.Delete(&model.PasswordResetCode{}, "Email = ?", email).Error; err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("delete repository executed successfully")
+	return nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type personalAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonalAccessTokenRepository(db *gorm.DB) model.PersonalAccessTokenRepository {
+	return personalAccessTokenRepository{
+		db: db,
+	}
+}
+
+func (pr personalAccessTokenRepository) Create(personalAccessToken model.PersonalAccessToken) error {
+	log := slog.With(
+		slog.String("func", "Create"),
+		slog.String("repository", "personalAccessToken"))
+
+	personalAccessToken.CreatedAt = time.Now()
+
+	if err := pr.db.Create(&personalAccessToken).Error; err != nil {
+		log.Error("Error to create personal access token in database: " + err.Error())
+		return err
+	}
+
+	log.Info("create repository executed successfully")
+	return nil
+}
+
+func (pr personalAccessTokenRepository) GetById(id string) (*model.PersonalAccessToken, error) {
+	log := slog.With(
+		slog.String("func", "GetById"),
+		slog.String("repository", "personalAccessToken"))
+
+	var personalAccessToken model.PersonalAccessToken
+	err := pr.db.Where("Id = ?", id).First(&personalAccessToken).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get by id repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &personalAccessToken, nil
+}
+
+func (pr personalAccessTokenRepository) GetByTokenHash(tokenHash string) (*model.PersonalAccessToken, error) {
+	log := slog.With(
+		slog.String("func", "GetByTokenHash"),
+		slog.String("repository", "personalAccessToken"))
+
+	var personalAccessToken model.PersonalAccessToken
+	err := pr.db.Where("TokenHash = ?", tokenHash).First(&personalAccessToken).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get by token hash repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &personalAccessToken, nil
+}
+
+func (pr personalAccessTokenRepository) GetActiveByUserId(userId string) ([]model.PersonalAccessToken, error) {
+	log := slog.With(
+		slog.String("func", "GetActiveByUserId"),
+		slog.String("repository", "personalAccessToken"))
+
+	var personalAccessTokens []model.PersonalAccessToken
+	err := pr.db.Where("UserId = ? AND RevokedAt IS NULL AND ExpiresAt > ?", userId, time.Now()).
+		Order("CreatedAt DESC").
+		Find(&personalAccessTokens).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get active by user id repository executed successfully")
+	return personalAccessTokens, nil
+}
+
+func (pr personalAccessTokenRepository) UpdateLastUsedAt(id string, lastUsedAt time.Time) error {
+	log := slog.With(
+		slog.String("func", "UpdateLastUsedAt"),
+		slog.String("repository", "personalAccessToken"))
+
+	err := pr.db.Model(&model.PersonalAccessToken{}).
+		Where("Id = ?", id).
+		Update("LastUsedAt", lastUsedAt).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("update last used at repository executed successfully")
+	return nil
+}
+
+func (pr personalAccessTokenRepository) Revoke(id string) error {
+	log := slog.With(
+		slog.String("func", "Revoke"),
+		slog.String("repository", "personalAccessToken"))
+
+	err := pr.db.Model(&model.PersonalAccessToken{}).
+		Where("Id = ? AND RevokedAt IS NULL", id).
+		Update("RevokedAt", time.Now()).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("revoke repository executed successfully")
+	return nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type profileRepository struct {
+	db *gorm.DB
+}
+
+func NewProfileRepository(db *gorm.DB) model.ProfileRepository {
+	return profileRepository{
+		db: db,
+	}
+}
+
+func (pr profileRepository) GetByUserId(userId string) (*model.Profile, error) {
+	log := slog.With(
+		slog.String("func", "GetByUserId"),
+		slog.String("repository", "profile"))
+
+	var profile model.Profile
+	err := pr.db.Where("UserId = ?", userId).First(&profile).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get by user id repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &profile, nil
+}
+
+func (pr profileRepository) Save(profile model.Profile) error {
+	log := slog.With(
+		slog.String("func", "Save"),
+		slog.String("repository", "profile"))
+
+	profile.LastModified = time.Now()
+
+	if err := pr.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&profile).Error; err != nil {
+		log.Error("Error to save profile in database: " + err.Error())
+		return err
+	}
+
+	log.Info("save repository executed successfully")
+	return nil
+}
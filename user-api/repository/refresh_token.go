@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) model.RefreshTokenRepository {
+	return refreshTokenRepository{
+		db: db,
+	}
+}
+
+func (rr refreshTokenRepository) Create(refreshToken model.RefreshToken) error {
+	log := slog.With(
+		slog.String("func", "Create"),
+		slog.String("repository", "refreshToken"))
+
+	refreshToken.CreatedAt = time.Now()
+
+	if err := rr.db.Create(&refreshToken).Error; err != nil {
+		log.Error("Error to create refresh token in database: " + err.Error())
+		return err
+	}
+
+	log.Info("create repository executed successfully")
+	return nil
+}
+
+func (rr refreshTokenRepository) GetByTokenHash(tokenHash string) (*model.RefreshToken, error) {
+	log := slog.With(
+		slog.String("func", "GetByTokenHash"),
+		slog.String("repository", "refreshToken"))
+
+	var refreshToken model.RefreshToken
+	err := rr.db.Where("TokenHash = ?", tokenHash).First(&refreshToken).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get by token hash repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &refreshToken, nil
+}
+
+func (rr refreshTokenRepository) MarkUsed(id string) (bool, error) {
+	log := slog.With(
+		slog.String("func", "MarkUsed"),
+		slog.String("repository", "refreshToken"))
+
+	result := rr.db.Model(&model.RefreshToken{}).
+		Where("Id = ? AND UsedAt IS NULL AND RevokedAt IS NULL", id).
+		Update("UsedAt", time.Now())
+
+	if result.Error != nil {
+		log.Error("Error: " + result.Error.Error())
+		return false, result.Error
+	}
+
+	log.Info("mark used repository executed successfully")
+	return result.RowsAffected == 1, nil
+}
+
+func (rr refreshTokenRepository) RevokeFamily(familyId string) error {
+	log := slog.With(
+		slog.String("func", "RevokeFamily"),
+		slog.String("repository", "refreshToken"))
+
+	err := rr.db.Model(&model.RefreshToken{}).
+		Where("FamilyId = ? AND RevokedAt IS NULL", familyId).
+		Update("RevokedAt", time.Now()).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("revoke family repository executed successfully")
+	return nil
+}
+
+func (rr refreshTokenRepository) RevokeAllForUser(userId string, exceptFamilyId string) error {
+	log := slog.With(
+		slog.String("func", "RevokeAllForUser"),
+		slog.String("repository", "refreshToken"))
+
+	err := rr.db.Model(&model.RefreshToken{}).
+		Where("UserId = ? AND FamilyId <> ? AND RevokedAt IS NULL", userId, exceptFamilyId).
+		Update("RevokedAt", time.Now()).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("revoke all for user repository executed successfully")
+	return nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type resendThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewResendThrottleRepository(db *gorm.DB) model.ResendThrottleStore {
+	return resendThrottleRepository{
+		db: db,
+	}
+}
+
+func (rr resendThrottleRepository) Save(throttle model.ResendThrottle) error {
+	log := slog.With(
+		slog.String("func", "Save"),
+		slog.String("repository", "resendThrottle"))
+
+	if err := rr.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&throttle).Error; err != nil {
+		log.Error("Error to save resend throttle in database: " + err.Error())
+		return err
+	}
+
+	if err := rr.db.Where("WindowEnd < ?", time.Now()).Delete(&model.ResendThrottle{}).Error; err != nil {
+		log.Warn("Error to purge expired resend throttles: " + err.Error())
+	}
+
+	log.Info("save repository executed successfully")
+	return nil
+}
+
+func (rr resendThrottleRepository) Get(key string) (*model.ResendThrottle, error) {
+	log := slog.With(
+		slog.String("func", "Get"),
+		slog.String("repository", "resendThrottle"))
+
+	var throttle model.ResendThrottle
+	err := rr.db.Where("`Key` = ?", key).First(&throttle).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &throttle, nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type revokedTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRevokedTokenRepository(db *gorm.DB) model.TokenRevocationStore {
+	return revokedTokenRepository{
+		db: db,
+	}
+}
+
+func (rr revokedTokenRepository) Revoke(jti string, expiresAt time.Time) (bool, error) {
+	log := slog.With(
+		slog.String("func", "Revoke"),
+		slog.String("repository", "revokedToken"))
+
+	revokedToken := model.RevokedToken{
+		Jti:       jti,
+		ExpiresAt: expiresAt,
+	}
+
+	result := rr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&revokedToken)
+	if result.Error != nil {
+		log.Error("Error to revoke token in database: " + result.Error.Error())
+		return false, result.Error
+	}
+
+	if err := rr.db.Where("ExpiresAt < ?", time.Now()).Delete(&model.RevokedToken{}).Error; err != nil {
+		log.Warn("Error to purge expired revoked tokens: " + err.Error())
+	}
+
+	log.Info("revoke repository executed successfully")
+	return result.RowsAffected == 1, nil
+}
+
+func (rr revokedTokenRepository) IsRevoked(jti string) (bool, error) {
+	log := slog.With(
+		slog.String("func", "IsRevoked"),
+		slog.String("repository", "revokedToken"))
+
+	var revokedToken model.RevokedToken
+	err := rr.db.Where("Jti = ?", jti).First(&revokedToken).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return false, err
+	}
+
+	return err == nil, nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type inMemoryRevokedTokenRepository struct {
+	mutex         sync.Mutex
+	revokedTokens map[string]time.Time
+}
+
+func NewInMemoryRevokedTokenRepository() model.TokenRevocationStore {
+	return &inMemoryRevokedTokenRepository{
+		revokedTokens: make(map[string]time.Time),
+	}
+}
+
+func (ir *inMemoryRevokedTokenRepository) Revoke(jti string, expiresAt time.Time) (bool, error) {
+	ir.mutex.Lock()
+	defer ir.mutex.Unlock()
+
+	now := time.Now()
+	for revokedJti, revokedExpiresAt := range ir.revokedTokens {
+		if now.After(revokedExpiresAt) {
+			delete(ir.revokedTokens, revokedJti)
+		}
+	}
+
+	if _, ok := ir.revokedTokens[jti]; ok {
+		return false, nil
+	}
+
+	ir.revokedTokens[jti] = expiresAt
+	return true, nil
+}
+
+func (ir *inMemoryRevokedTokenRepository) IsRevoked(jti string) (bool, error) {
+	ir.mutex.Lock()
+	defer ir.mutex.Unlock()
+
+	_, ok := ir.revokedTokens[jti]
+	return ok, nil
+}
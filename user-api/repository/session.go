@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) model.SessionRepository {
+	return sessionRepository{
+		db: db,
+	}
+}
+
+func (sr sessionRepository) Create(session model.Session) error {
+	log := slog.With(
+		slog.String("func", "Create"),
+		slog.String("repository", "session"))
+
+	session.CreatedAt = time.Now()
+	session.LastSeenAt = session.CreatedAt
+
+	if err := sr.db.Create(&session).Error; err != nil {
+		log.Error("Error to create session in database: " + err.Error())
+		return err
+	}
+
+	log.Info("create repository executed successfully")
+	return nil
+}
+
+func (sr sessionRepository) GetById(id string) (*model.Session, error) {
+	log := slog.With(
+		slog.String("func", "GetById"),
+		slog.String("repository", "session"))
+
+	var session model.Session
+	err := sr.db.Where("Id = ?", id).First(&session).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get by id repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &session, nil
+}
+
+func (sr sessionRepository) GetActiveByUserId(userId string) ([]model.Session, error) {
+	log := slog.With(
+		slog.String("func", "GetActiveByUserId"),
+		slog.String("repository", "session"))
+
+	var sessions []model.Session
+	err := sr.db.Where("UserId = ? AND RevokedAt IS NULL AND ExpiresAt > ?", userId, time.Now()).
+		Order("LastSeenAt DESC").
+		Find(&sessions).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get active by user id repository executed successfully")
+	return sessions, nil
+}
+
+func (sr sessionRepository) Touch(id string, lastSeenAt time.Time) error {
+	log := slog.With(
+		slog.String("func", "Touch"),
+		slog.String("repository", "session"))
+
+	err := sr.db.Model(&model.Session{}).
+		Where("Id = ?", id).
+		Update("LastSeenAt", lastSeenAt).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("touch repository executed successfully")
+	return nil
+}
+
+func (sr sessionRepository) Revoke(id string) error {
+	log := slog.With(
+		slog.String("func", "Revoke"),
+		slog.String("repository", "session"))
+
+	err := sr.db.Model(&model.Session{}).
+		Where("Id = ? AND RevokedAt IS NULL", id).
+		Update("RevokedAt", time.Now()).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("revoke repository executed successfully")
+	return nil
+}
+
+func (sr sessionRepository) RevokeAllForUser(userId string, exceptId string) error {
+	log := slog.With(
+		slog.String("func", "RevokeAllForUser"),
+		slog.String("repository", "session"))
+
+	err := sr.db.Model(&model.Session{}).
+		Where("UserId = ? AND Id <> ? AND RevokedAt IS NULL", userId, exceptId).
+		Update("RevokedAt", time.Now()).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("revoke all for user repository executed successfully")
+	return nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type totpRepository struct {
+	db *gorm.DB
+}
+
+func NewTOTPRepository(db *gorm.DB) model.TOTPRepository {
+	return totpRepository{
+		db: db,
+	}
+}
+
+func (tr totpRepository) Save(userTOTP model.UserTOTP) error {
+	log := slog.With(
+		slog.String("func", "Save"),
+		slog.String("repository", "totp"))
+
+	userTOTP.CreatedAt = time.Now()
+
+	if err := tr.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&userTOTP).Error; err != nil {
+		log.Error("Error to save totp in database: " + err.Error())
+		return err
+	}
+
+	log.Info("save repository executed successfully")
+	return nil
+}
+
+func (tr totpRepository) GetByUserId(userId string) (*model.UserTOTP, error) {
+	log := slog.With(
+		slog.String("func", "GetByUserId"),
+		slog.String("repository", "totp"))
+
+	var userTOTP model.UserTOTP
+	err := tr.db.Where("UserId = ?", userId).First(&userTOTP).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get by user id repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &userTOTP, nil
+}
+
+func (tr totpRepository) Enable(userId string) error {
+	log := slog.With(
+		slog.String("func", "Enable"),
+		slog.String("repository", "totp"))
+
+	err := tr.db.Model(&model.UserTOTP{}).Where("UserId = ?", userId).Update("Enabled", true).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("enable repository executed successfully")
+	return nil
+}
+
+func (tr totpRepository) UpdateLastUsedStep(userId string, step int64) (bool, error) {
+	log := slog.With(
+		slog.String("func", "UpdateLastUsedStep"),
+		slog.String("repository", "totp"))
+
+	result := tr.db.Model(&model.UserTOTP{}).
+		Where("UserId = ? AND LastUsedStep < ?", userId, step).
+		Update("LastUsedStep", step)
+
+	if result.Error != nil {
+		log.Error("Error: " + result.Error.Error())
+		return false, result.Error
+	}
+
+	log.Info("update last used step repository executed successfully")
+	return result.RowsAffected == 1, nil
+}
+
+func (tr totpRepository) Delete(userId string) error {
+	log := slog.With(
+		slog.String("func", "Delete"),
+		slog.String("repository", "totp"))
+
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.RecoveryCode{}, "UserId = ?", userId).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&model.UserTOTP{}, "UserId = ?", userId).Error
+	})
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("delete repository executed successfully")
+	return nil
+}
+
+func (tr totpRepository) ReplaceRecoveryCodes(userId string, codeHashes []string) error {
+	log := slog.With(
+		slog.String("func", "ReplaceRecoveryCodes"),
+		slog.String("repository", "totp"))
+
+	now := time.Now()
+	recoveryCodes := make([]model.RecoveryCode, 0, len(codeHashes))
+	for _, codeHash := range codeHashes {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			log.Error("Error: " + err.Error())
+			return err
+		}
+
+		recoveryCodes = append(recoveryCodes, model.RecoveryCode{
+			Id:        id.String(),
+			UserId:    userId,
+			CodeHash:  codeHash,
+			CreatedAt: now,
+		})
+	}
+
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.RecoveryCode{}, "UserId = ?", userId).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&recoveryCodes).Error
+	})
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("replace recovery codes repository executed successfully")
+	return nil
+}
+
+func (tr totpRepository) UseRecoveryCode(userId string, codeHash string) (bool, error) {
+	log := slog.With(
+		slog.String("func", "UseRecoveryCode"),
+		slog.String("repository", "totp"))
+
+	result := tr.db.Model(&model.RecoveryCode{}).
+		Where("UserId = ? AND CodeHash = ? AND UsedAt IS NULL", userId, codeHash).
+		Update("UsedAt", time.Now())
+
+	if result.Error != nil {
+		log.Error("Error: " + result.Error.Error())
+		return false, result.Error
+	}
+
+	log.Info("use recovery code repository executed successfully")
+	return result.RowsAffected == 1, nil
+}
@@ -3,18 +3,23 @@ package repository
 import (
 	"errors"
 	"log/slog"
+	"strings"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 
-	"github.com/OVillas/user-api/config/database"
 	"github.com/OVillas/user-api/model"
 )
 
-type userRepository struct{}
+type userRepository struct {
+	db *gorm.DB
+}
 
-func NewUserRepository() model.UserRepository {
-	return userRepository{}
+func NewUserRepository(db *gorm.DB) model.UserRepository {
+	return userRepository{
+		db: db,
+	}
 }
 
 func (ur userRepository) Create(user model.User) error {
@@ -22,21 +27,18 @@ func (ur userRepository) Create(user model.User) error {
 		slog.String("func", "Create"),
 		slog.String("repository", "user"))
 
-	db, err := database.NewMysqlConnection()
-	if err != nil {
-		log.Error("Error connecting to the database", err)
-		return err
-	}
-
 	now := time.Now()
 
 	user.CreatedAt = now
 	user.LastModified = now
 
-	result := db.Create(&user)
+	result := ur.db.Create(&user)
 
 	if result.Error != nil {
-		log.Error("Error to create user in database", result.Error)
+		log.Error("Error to create user in database: " + result.Error.Error())
+		if isDuplicateKey(result.Error, "Matricula") {
+			return model.ErrMatriculaAlreadyRegistered
+		}
 		return result.Error
 	}
 
@@ -49,19 +51,14 @@ func (ur userRepository) GetAll() ([]model.User, error) {
 		slog.String("func", "GetAll"),
 		slog.String("repository", "user"))
 
-	db, err := database.NewMysqlConnection()
-	if err != nil {
-		log.Error("Error connecting to the database", err)
-		return nil, err
-	}
 	var users []model.User
 
-	result := db.Find(&users)
+	result := ur.db.Find(&users)
 
-	err = result.Error
+	err := result.Error
 
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return nil, err
 	}
 
@@ -78,14 +75,8 @@ func (ur userRepository) GetById(id string) (*model.User, error) {
 		slog.String("func", "GetById"),
 		slog.String("repository", "user"))
 
-	db, err := database.NewMysqlConnection()
-	if err != nil {
-		log.Error("Error connecting to the database", err)
-		return nil, err
-	}
-
 	var user model.User
-	err = db.Where("id = ?", id).First(&user).Error
+	err := ur.db.Where("id = ?", id).First(&user).Error
 
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
@@ -104,18 +95,12 @@ func (ur userRepository) GetByName(name string) ([]model.User, error) {
 		slog.String("func", "GetByName"),
 		slog.String("repository", "user"))
 
-	db, err := database.NewMysqlConnection()
-	if err != nil {
-		log.Error("Error connecting to the database", err)
-		return nil, err
-	}
-
 	var users []model.User
 
-	err = db.Where("name LIKE ?", "%"+name+"%").Find(&users).Error
+	err := ur.db.Where("name LIKE ?", "%"+name+"%").Find(&users).Error
 
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return nil, err
 	}
 
@@ -132,21 +117,36 @@ func (ur userRepository) GetByEmail(email string) (*model.User, error) {
 		slog.String("func", "GetByEmail"),
 		slog.String("repository", "user"))
 
-	db, err := database.NewMysqlConnection()
-	if err != nil {
-		log.Error("Error connecting to the database", err)
+	var user model.User
+	err := ur.db.Where("email = ?", email).First(&user).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
 		return nil, err
 	}
 
+	log.Info("get by email repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &user, nil
+}
+
+func (ur userRepository) GetByMatricula(matricula string) (*model.User, error) {
+	log := slog.With(
+		slog.String("func", "GetByMatricula"),
+		slog.String("repository", "user"))
+
 	var user model.User
-	err = db.Where("email = ?", email).First(&user).Error
+	err := ur.db.Where("Matricula = ?", matricula).First(&user).Error
 
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return nil, err
 	}
 
-	log.Info("get by email repository executed successfully")
+	log.Info("get by matricula repository executed successfully")
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -159,80 +159,190 @@ func (ur userRepository) Update(id string, user model.User) error {
 		slog.String("func", "Create"),
 		slog.String("repository", "user"))
 
-	db, err := database.NewMysqlConnection()
+	// The flag is written even when false, so a replaced matricula does not
+	// keep the verification of the previous one.
+	err := ur.db.Model(&model.User{}).Where("id = ?", id).
+		Select("Name", "Email", "Matricula", "MatriculaVerified").
+		Updates(model.User{Name: user.Name, Email: user.Email, Matricula: user.Matricula, MatriculaVerified: user.MatriculaVerified}).Error
 	if err != nil {
-		log.Error("Error connecting to the database", err)
+		log.Error("Error: " + err.Error())
+		if isDuplicateKey(err, "Matricula") {
+			return model.ErrMatriculaAlreadyRegistered
+		}
 		return err
 	}
 
-	err = db.Model(&model.User{}).Where("id = ?", id).Updates(model.User{Name: user.Name, Email: user.Email}).Error
+	log.Info("update repository executed successfully")
+	return nil
+}
+
+func (ur userRepository) Delete(id string) error {
+	log := slog.With(
+		slog.String("func", "Delete"),
+		slog.String("repository", "user"))
+
+	err := ur.db.Delete(&model.User{}, "id = ?", id).Error
 	if err != nil {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return err
 	}
 
-	log.Info("update repository executed successfully")
+	log.Info("delete repository executed successfully")
 	return nil
 }
 
-func (ur userRepository) Delete(id string) error {
+func (ur userRepository) UpdatePassword(id string, password string) error {
 	log := slog.With(
-		slog.String("func", "Delete"),
+		slog.String("func", "updatePassword"),
 		slog.String("repository", "user"))
 
-	db, err := database.NewMysqlConnection()
+	err := ur.db.Model(&model.User{}).Where("id = ?", id).Updates(model.User{Password: password}).Error
 	if err != nil {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return err
 	}
-	err = db.Delete(&model.User{}, "id = ?", id).Error
+
+	log.Info("update password repository executed successfully")
+	return nil
+}
+
+func (ur userRepository) UpdateConfirmedEmail(id string, affiliation string) error {
+	log := slog.With(
+		slog.String("func", "UpdateConfirmedEmail"),
+		slog.String("repository", "user"))
+
+	err := ur.db.Model(&model.User{}).Where("id = ?", id).Updates(model.User{IsEmailConfirmed: true, Affiliation: affiliation}).Error
 	if err != nil {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return err
 	}
 
-	log.Info("delete repository executed successfully")
+	log.Info("update confirmed email repository executed successfully")
 	return nil
 }
 
-func (ur userRepository) UpdatePassword(id string, password string) error {
+func (ur userRepository) UpdateMatriculaVerified(id string, verified bool) error {
 	log := slog.With(
-		slog.String("func", "updatePassword"),
+		slog.String("func", "UpdateMatriculaVerified"),
 		slog.String("repository", "user"))
 
-	db, err := database.NewMysqlConnection()
+	err := ur.db.Model(&model.User{}).Where("id = ?", id).Update("MatriculaVerified", verified).Error
 	if err != nil {
-		log.Error("Error connecting to the database", err)
+		log.Error("Error: " + err.Error())
 		return err
 	}
 
-	err = db.Model(&model.User{}).Where("id = ?", id).Updates(model.User{Password: password}).Error
+	log.Info("update matricula verified repository executed successfully")
+	return nil
+}
+
+func (ur userRepository) UpdateEmail(id string, email string, affiliation string) error {
+	log := slog.With(
+		slog.String("func", "UpdateEmail"),
+		slog.String("repository", "user"))
+
+	err := ur.db.Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"Email":            email,
+		"Affiliation":      affiliation,
+		"IsEmailConfirmed": true,
+	}).Error
 	if err != nil {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return err
 	}
 
-	log.Info("update password repository executed successfully")
+	log.Info("update email repository executed successfully")
 	return nil
 }
 
-func (ur userRepository) UpdateConfirmedEmail(id string) error {
+func (ur userRepository) UpdateRole(id string, role string) error {
 	log := slog.With(
-		slog.String("func", "UpdateConfirmedEmail"),
+		slog.String("func", "UpdateRole"),
 		slog.String("repository", "user"))
 
-	db, err := database.NewMysqlConnection()
+	err := ur.db.Model(&model.User{}).Where("id = ?", id).Updates(model.User{Role: role}).Error
 	if err != nil {
-		log.Error("Error connecting to the database", err)
+		log.Error("Error: " + err.Error())
 		return err
 	}
 
-	err = db.Model(&model.User{}).Where("id = ?", id).Updates(model.User{IsEmailConfirmed: true}).Error
+	log.Info("update role repository executed successfully")
+	return nil
+}
+
+func (ur userRepository) CountByRole(role string) (int64, error) {
+	log := slog.With(
+		slog.String("func", "CountByRole"),
+		slog.String("repository", "user"))
+
+	var count int64
+	if err := ur.db.Model(&model.User{}).Where("Role = ?", role).Count(&count).Error; err != nil {
+		log.Error("Error: " + err.Error())
+		return 0, err
+	}
+
+	log.Info("count by role repository executed successfully")
+	return count, nil
+}
+
+func (ur userRepository) UpdateAvatar(id string, avatarId string) error {
+	log := slog.With(
+		slog.String("func", "UpdateAvatar"),
+		slog.String("repository", "user"))
+
+	err := ur.db.Model(&model.User{}).Where("id = ?", id).Updates(model.User{AvatarId: &avatarId}).Error
 	if err != nil {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return err
 	}
 
-	log.Info("update confirmed email repository executed successfully")
+	log.Info("update avatar repository executed successfully")
+	return nil
+}
+
+func (ur userRepository) GetByGoogleId(googleId string) (*model.User, error) {
+	log := slog.With(
+		slog.String("func", "GetByGoogleId"),
+		slog.String("repository", "user"))
+
+	var user model.User
+	err := ur.db.Where("GoogleId = ?", googleId).First(&user).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get by google id repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &user, nil
+}
+
+func (ur userRepository) UpdateGoogleId(id string, googleId string) error {
+	log := slog.With(
+		slog.String("func", "UpdateGoogleId"),
+		slog.String("repository", "user"))
+
+	err := ur.db.Model(&model.User{}).Where("id = ?", id).Updates(model.User{GoogleId: &googleId, IsEmailConfirmed: true}).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("update google id repository executed successfully")
 	return nil
 }
+
+// isDuplicateKey reports a UNIQUE violation on column, which is how two
+// concurrent requests that both passed the service checks end up.
+func isDuplicateKey(err error, column string) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1062 {
+		return false
+	}
+
+	return strings.HasSuffix(mysqlErr.Message, column+"'")
+}
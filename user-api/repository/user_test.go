@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDuplicateKey(t *testing.T) {
+	duplicateMatricula := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '202310012345' for key 'Users.Matricula'"}
+	duplicateEmail := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'ana@uerj.br' for key 'Users.Email'"}
+
+	assert.True(t, isDuplicateKey(duplicateMatricula, "Matricula"))
+	assert.True(t, isDuplicateKey(fmt.Errorf("update: %w", duplicateMatricula), "Matricula"))
+	assert.False(t, isDuplicateKey(duplicateEmail, "Matricula"))
+	assert.False(t, isDuplicateKey(&mysql.MySQLError{Number: 1064, Message: "syntax error near 'Matricula'"}, "Matricula"))
+	assert.False(t, isDuplicateKey(errors.New("Matricula'"), "Matricula"))
+}
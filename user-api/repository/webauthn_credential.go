@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type webAuthnCredentialRepository struct {
+	db *gorm.DB
+}
+
+func NewWebAuthnCredentialRepository(db *gorm.DB) model.WebAuthnCredentialRepository {
+	return webAuthnCredentialRepository{
+		db: db,
+	}
+}
+
+func (wr webAuthnCredentialRepository) Create(credential model.WebAuthnCredential) error {
+	log := slog.With(
+		slog.String("func", "Create"),
+		slog.String("repository", "webAuthnCredential"))
+
+	credential.CreatedAt = time.Now()
+
+	if err := wr.db.Create(&credential).Error; err != nil {
+		log.Error("Error to create webauthn credential in database: " + err.Error())
+		return err
+	}
+
+	log.Info("create repository executed successfully")
+	return nil
+}
+
+func (wr webAuthnCredentialRepository) GetById(id string) (*model.WebAuthnCredential, error) {
+	log := slog.With(
+		slog.String("func", "GetById"),
+		slog.String("repository", "webAuthnCredential"))
+
+	var credential model.WebAuthnCredential
+	err := wr.db.Where("Id = ?", id).First(&credential).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get by id repository executed successfully")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return &credential, nil
+}
+
+func (wr webAuthnCredentialRepository) GetByUserId(userId string) ([]model.WebAuthnCredential, error) {
+	log := slog.With(
+		slog.String("func", "GetByUserId"),
+		slog.String("repository", "webAuthnCredential"))
+
+	var credentials []model.WebAuthnCredential
+	err := wr.db.Where("UserId = ?", userId).Find(&credentials).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("get by user id repository executed successfully")
+	return credentials, nil
+}
+
+func (wr webAuthnCredentialRepository) UpdateSignCount(id string, signCount uint32) error {
+	log := slog.With(
+		slog.String("func", "UpdateSignCount"),
+		slog.String("repository", "webAuthnCredential"))
+
+	err := wr.db.Model(&model.WebAuthnCredential{}).
+		Where("Id = ?", id).
+		Updates(map[string]interface{}{"SignCount": signCount, "LastUsedAt": time.Now()}).Error
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("update sign count repository executed successfully")
+	return nil
+}
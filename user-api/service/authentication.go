@@ -1,64 +1,362 @@
 package service
 
 import (
+	"crypto/subtle"
 	"fmt"
+	"github.com/OVillas/user-api/config"
 	"github.com/OVillas/user-api/model"
 	"github.com/OVillas/user-api/util"
+	"github.com/google/uuid"
 	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 )
 
-var confirmationsCodes map[string]model.ConfirmationCode
-
-func init() {
-	confirmationsCodes = make(map[string]model.ConfirmationCode)
-}
+const (
+	passwordResetCodeExpiry      = 15 * time.Minute
+	passwordResetCodeMaxAttempts = 5
+	passwordResetResendCooldown  = time.Minute
+	confirmationCodeMaxAttempts  = 5
+	confirmationResendCooldown   = time.Minute
+	confirmationResendDailyLimit = 5
+	magicLinkResendCooldown      = time.Minute
+	magicLinkDailyLimit          = 10
+	sessionUserAgentMaxLength    = 512
+)
 
 type authenticationService struct {
-	userRepository model.UserRepository
-	emailService   model.EmailService
+	userRepository         model.UserRepository
+	refreshTokenRepository model.RefreshTokenRepository
+	sessionRepository      model.SessionRepository
+	tokenRevocationStore   model.TokenRevocationStore
+	confirmationCodeStore  model.ConfirmationCodeStore
+	passwordResetCodeStore model.PasswordResetCodeStore
+	resendThrottleStore    model.ResendThrottleStore
+	loginAttemptStore      model.LoginAttemptStore
+	loginHistoryService    model.LoginHistoryService
+	totpRepository         model.TOTPRepository
+	emailService           model.EmailService
+	emailDomainPolicy      model.EmailDomainPolicy
+	enrollmentVerifier     model.EnrollmentVerifier
 }
 
-func NewAuthenticationService(userRepository model.UserRepository, emailService model.EmailService) model.AuthenticationService {
+func NewAuthenticationService(
+	userRepository model.UserRepository,
+	refreshTokenRepository model.RefreshTokenRepository,
+	sessionRepository model.SessionRepository,
+	tokenRevocationStore model.TokenRevocationStore,
+	confirmationCodeStore model.ConfirmationCodeStore,
+	passwordResetCodeStore model.PasswordResetCodeStore,
+	resendThrottleStore model.ResendThrottleStore,
+	loginAttemptStore model.LoginAttemptStore,
+	loginHistoryService model.LoginHistoryService,
+	totpRepository model.TOTPRepository,
+	emailService model.EmailService,
+	emailDomainPolicy model.EmailDomainPolicy,
+	enrollmentVerifier model.EnrollmentVerifier,
+) model.AuthenticationService {
 	return &authenticationService{
-		userRepository: userRepository,
-		emailService:   emailService,
+		userRepository:         userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		sessionRepository:      sessionRepository,
+		tokenRevocationStore:   tokenRevocationStore,
+		confirmationCodeStore:  confirmationCodeStore,
+		passwordResetCodeStore: passwordResetCodeStore,
+		resendThrottleStore:    resendThrottleStore,
+		loginAttemptStore:      loginAttemptStore,
+		loginHistoryService:    loginHistoryService,
+		totpRepository:         totpRepository,
+		emailService:           emailService,
+		emailDomainPolicy:      emailDomainPolicy,
+		enrollmentVerifier:     enrollmentVerifier,
 	}
 }
 
-func (a *authenticationService) Login(login model.Login) (string, error) {
+func (a *authenticationService) Login(login model.Login, client model.ClientInfo) (*model.TokenPair, error) {
 	log := slog.With(
 		slog.String("func", "Login"),
 		slog.String("service", "authentication"))
 
+	if err := a.CheckLoginThrottle(login.Email, client.IP); err != nil {
+		log.Warn("Login throttled for email: " + login.Email + " ip: " + client.IP)
+		return nil, err
+	}
+
 	user, err := a.userRepository.GetByEmail(login.Email)
 	if err != nil {
 		log.Warn("Failed to obtain user by email")
-		return "", model.ErrGetUser
+		return nil, model.ErrGetUser
 	}
 
 	if user == nil {
 		log.Warn("User not found with this email: " + login.Email)
-		return "", model.ErrUserNotFound
+		a.RegisterLoginFailure(login.Email, client.IP, nil)
+		return nil, model.ErrUserNotFound
 	}
 
 	if err := CheckPassword(user.Password, login.Password); err != nil {
 		log.Warn("invalid password for email: " + user.Email)
-		return "", model.ErrPasswordNotMatch
+		a.RegisterLoginFailure(login.Email, client.IP, user)
+		if err := a.loginHistoryService.Record(*user, client, model.LoginMethodPassword, false); err != nil {
+			log.Warn("Error to record login failure: " + err.Error())
+		}
+		return nil, model.ErrPasswordNotMatch
+	}
+
+	if err := a.ResetLoginFailures(login.Email); err != nil {
+		log.Warn("Error to reset login attempts: " + err.Error())
+	}
+
+	return a.CompleteLogin(*user, client, model.LoginMethodPassword, login.Scopes)
+}
+
+// CompleteLogin issues tokens for a user whose first factor was verified, or
+// asks for the second factor when the user has TOTP enabled.
+func (a *authenticationService) CompleteLogin(user model.User, client model.ClientInfo, method string, scopes []string) (*model.TokenPair, error) {
+	log := slog.With(
+		slog.String("func", "CompleteLogin"),
+		slog.String("service", "authentication"))
+
+	scopes, err := grantScopes(util.UserScopes(user), scopes)
+	if err != nil {
+		log.Warn("Requested scopes not allowed for user: " + user.Id)
+		return nil, err
 	}
 
-	token, err := util.CreateToken(*user)
+	userTOTP, err := a.totpRepository.GetByUserId(user.Id)
 	if err != nil {
-		log.Error("error trying create token jwt. Error: ", err)
-		return "", model.ErrGenToken
+		log.Error("Error: " + err.Error())
+		return nil, err
 	}
 
-	return token, nil
+	if userTOTP != nil && userTOTP.Enabled {
+		mfaToken, err := util.CreateMFAToken(user, scopes)
+		if err != nil {
+			log.Error("error trying create mfa token. Error: " + err.Error())
+			return nil, model.ErrGenMFAToken
+		}
+
+		log.Info("Second factor required for user: " + user.Id)
+		return &model.TokenPair{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	return a.IssueTokens(user, client, method, scopes)
 }
 
-func (a *authenticationService) UpdatePassword(id string, updatePassword model.UpdatePassword) error {
+// IssueTokens opens a session limited to the requested scopes, or to every
+// scope of the user role when none was requested.
+func (a *authenticationService) IssueTokens(user model.User, client model.ClientInfo, method string, scopes []string) (*model.TokenPair, error) {
 	log := slog.With(
-		slog.String("func", "Login"),
+		slog.String("func", "IssueTokens"),
+		slog.String("service", "authentication"))
+
+	scopes, err := grantScopes(util.UserScopes(user), scopes)
+	if err != nil {
+		log.Warn("Requested scopes not allowed for user: " + user.Id)
+		return nil, err
+	}
+
+	sessionId, err := uuid.NewRandom()
+	if err != nil {
+		log.Error("error trying create session id. Error: " + err.Error())
+		return nil, model.ErrCreateSession
+	}
+
+	err = a.sessionRepository.Create(model.Session{
+		Id:        sessionId.String(),
+		UserId:    user.Id,
+		UserAgent: truncate(client.UserAgent, sessionUserAgentMaxLength),
+		IP:        client.IP,
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: time.Now().Add(config.SessionMaxLifetime),
+	})
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrCreateSession
+	}
+
+	if err := a.loginHistoryService.Record(user, client, method, true); err != nil {
+		log.Warn("Error to record login: " + err.Error())
+	}
+
+	return a.issueTokenPair(user, sessionId.String(), scopes)
+}
+
+// Refresh rotates the refresh token. The new access token may be narrowed to
+// a subset of the scopes granted when the session was opened.
+func (a *authenticationService) Refresh(refreshToken string, scopes []string) (*model.TokenPair, error) {
+	log := slog.With(
+		slog.String("func", "Refresh"),
+		slog.String("service", "authentication"))
+
+	storedToken, err := a.refreshTokenRepository.GetByTokenHash(util.HashToken(refreshToken))
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	if storedToken == nil {
+		log.Warn("Refresh token not found")
+		return nil, model.ErrInvalidRefreshToken
+	}
+
+	if storedToken.UsedAt != nil || storedToken.RevokedAt != nil {
+		log.Warn("Refresh token reuse detected, revoking family: " + storedToken.FamilyId)
+		if err := a.revokeSession(storedToken.FamilyId); err != nil {
+			log.Error("Error: " + err.Error())
+			return nil, err
+		}
+		return nil, model.ErrRefreshTokenReused
+	}
+
+	if time.Now().After(storedToken.ExpiresAt) {
+		log.Warn("Refresh token expired")
+		return nil, model.ErrInvalidRefreshToken
+	}
+
+	session, err := a.sessionRepository.GetById(storedToken.FamilyId)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	if session == nil || !session.IsActive(time.Now()) {
+		log.Warn("Refresh token belongs to a revoked or expired session: " + storedToken.FamilyId)
+		return nil, model.ErrInvalidRefreshToken
+	}
+
+	scopes, err = grantScopes(strings.Fields(session.Scopes), scopes)
+	if err != nil {
+		log.Warn("Refresh requested scopes beyond the session: " + session.Id)
+		return nil, err
+	}
+
+	marked, err := a.refreshTokenRepository.MarkUsed(storedToken.Id)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	if !marked {
+		log.Warn("Refresh token used concurrently, revoking family: " + storedToken.FamilyId)
+		if err := a.revokeSession(storedToken.FamilyId); err != nil {
+			log.Error("Error: " + err.Error())
+			return nil, err
+		}
+		return nil, model.ErrRefreshTokenReused
+	}
+
+	user, err := a.userRepository.GetById(storedToken.UserId)
+	if err != nil {
+		log.Error("failed to get user by id")
+		return nil, model.ErrGetUser
+	}
+
+	if user == nil {
+		log.Warn("User not found with this id")
+		return nil, model.ErrUserNotFound
+	}
+
+	if err := a.sessionRepository.Touch(session.Id, time.Now()); err != nil {
+		log.Warn("Error to update session last seen: " + err.Error())
+	}
+
+	log.Info("Refresh token rotated successfully")
+	return a.issueTokenPair(*user, storedToken.FamilyId, keepAllowedScopes(scopes, util.UserScopes(*user)))
+}
+
+func (a *authenticationService) Logout(userId string, sessionId string, jti string, expiresAt time.Time, refreshToken string) error {
+	log := slog.With(
+		slog.String("func", "Logout"),
+		slog.String("service", "authentication"))
+
+	if _, err := a.tokenRevocationStore.Revoke(jti, expiresAt); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrRevokeToken
+	}
+
+	if err := a.sessionRepository.Revoke(sessionId); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrRevokeSession
+	}
+
+	if err := a.refreshTokenRepository.RevokeFamily(sessionId); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrRevokeToken
+	}
+
+	if refreshToken == "" {
+		log.Info("Logout executed successfully")
+		return nil
+	}
+
+	storedToken, err := a.refreshTokenRepository.GetByTokenHash(util.HashToken(refreshToken))
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrRevokeToken
+	}
+
+	if storedToken == nil || storedToken.UserId != userId {
+		log.Warn("Refresh token not found for this user")
+		return nil
+	}
+
+	if err := a.refreshTokenRepository.RevokeFamily(storedToken.FamilyId); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrRevokeToken
+	}
+
+	log.Info("Logout executed successfully")
+	return nil
+}
+
+func (a *authenticationService) issueTokenPair(user model.User, sessionId string, scopes []string) (*model.TokenPair, error) {
+	log := slog.With(
+		slog.String("func", "issueTokenPair"),
+		slog.String("service", "authentication"))
+
+	accessToken, err := util.CreateToken(user, sessionId, scopes)
+	if err != nil {
+		log.Error("error trying create token jwt. Error: " + err.Error())
+		return nil, model.ErrGenToken
+	}
+
+	refreshToken, err := util.GenerateRefreshToken()
+	if err != nil {
+		log.Error("error trying create refresh token. Error: " + err.Error())
+		return nil, model.ErrGenRefreshToken
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		log.Error("error trying create refresh token id. Error: " + err.Error())
+		return nil, model.ErrGenRefreshToken
+	}
+
+	err = a.refreshTokenRepository.Create(model.RefreshToken{
+		Id:        id.String(),
+		UserId:    user.Id,
+		FamilyId:  sessionId,
+		TokenHash: util.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(config.RefreshTokenTTL),
+	})
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrGenRefreshToken
+	}
+
+	return &model.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(config.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+func (a *authenticationService) UpdatePassword(id string, sessionId string, updatePassword model.UpdatePassword) error {
+	log := slog.With(
+		slog.String("func", "UpdatePassword"),
 		slog.String("service", "authentication"))
 
 	user, err := a.userRepository.GetById(id)
@@ -84,10 +382,15 @@ func (a *authenticationService) UpdatePassword(id string, updatePassword model.U
 	}
 
 	if err := a.userRepository.UpdatePassword(id, string(newHashedPassword)); err != nil {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return model.ErrUpdatePassword
 	}
 
+	if err := a.revokeUserSessions(id, sessionId); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrRevokeSession
+	}
+
 	log.Info("Password updated successfully")
 	return nil
 }
@@ -98,11 +401,15 @@ func (a *authenticationService) SendConfirmationEmailCode(email string) error {
 		slog.String("service", "authentication"))
 
 	otp := model.ConfirmationCode{
+		Email:      email,
 		Code:       util.GenerateOTP(6),
 		ExpiryTime: time.Now().Add(time.Hour),
 	}
 
-	a.addOrUpdateConfirmationCode(email, otp)
+	if err := a.confirmationCodeStore.Save(otp); err != nil {
+		log.Error("Errors: " + err.Error())
+		return model.ErrToSendConfirmationCode
+	}
 
 	subject := "Confirmação de cadastro"
 	content := fmt.Sprintf("<h1>Olá!</h1><p>Seu código de confirmação é: <h2><b>%s</b></h2></p>", otp.Code)
@@ -110,7 +417,7 @@ func (a *authenticationService) SendConfirmationEmailCode(email string) error {
 
 	err := a.emailService.SendEmail(subject, content, to)
 	if err != nil {
-		log.Error("Errors: ", err)
+		log.Error("Errors: " + err.Error())
 		return model.ErrToSendConfirmationCode
 	}
 	log.Info("Confirmation send successfully")
@@ -118,6 +425,68 @@ func (a *authenticationService) SendConfirmationEmailCode(email string) error {
 	return nil
 }
 
+func (a *authenticationService) ResendConfirmationEmailCode(email string) error {
+	log := slog.With(
+		slog.String("func", "ResendConfirmationEmailCode"),
+		slog.String("service", "authentication"))
+
+	if err := a.registerConfirmationCodeResend(email); err != nil {
+		log.Warn("Confirmation code resend throttled for email: " + email)
+		return err
+	}
+
+	user, err := a.userRepository.GetByEmail(email)
+	if err != nil {
+		log.Warn("Failed to obtain user by email")
+		return model.ErrGetUser
+	}
+
+	if user == nil {
+		log.Warn("User not found with this email: " + email)
+		return nil
+	}
+
+	if user.IsEmailConfirmed {
+		log.Warn("Email already confirmed: " + email)
+		return nil
+	}
+
+	return a.SendConfirmationEmailCode(email)
+}
+
+func (a *authenticationService) registerConfirmationCodeResend(email string) error {
+	return a.registerResend("confirmation:"+normalizeEmail(email), confirmationResendCooldown, confirmationResendDailyLimit)
+}
+
+func (a *authenticationService) registerResend(key string, cooldown time.Duration, dailyLimit int) error {
+	now := time.Now()
+
+	resend, err := a.resendThrottleStore.Get(key)
+	if err != nil {
+		return err
+	}
+
+	if resend == nil || now.After(resend.WindowEnd) {
+		resend = &model.ResendThrottle{
+			Key:       key,
+			WindowEnd: now.Add(24 * time.Hour),
+		}
+	}
+
+	if now.Sub(resend.LastSentAt) < cooldown {
+		return model.ErrResendCooldown
+	}
+
+	if resend.Count >= dailyLimit {
+		return model.ErrResendDailyLimit
+	}
+
+	resend.LastSentAt = now
+	resend.Count++
+
+	return a.resendThrottleStore.Save(*resend)
+}
+
 func (a *authenticationService) ConfirmEmail(confirmCodeEmail model.ConfirmCodeEmail) error {
 	log := slog.With(
 		slog.String("func", "ConfirmEmail"),
@@ -134,8 +503,13 @@ func (a *authenticationService) ConfirmEmail(confirmCodeEmail model.ConfirmCodeE
 		return model.ErrUserNotFound
 	}
 
-	confirmationCode, ok := confirmationsCodes[confirmCodeEmail.Email]
-	if !ok {
+	confirmationCode, err := a.confirmationCodeStore.Get(confirmCodeEmail.Email)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	if confirmationCode == nil {
 		log.Error("OTP not found with this email: " + confirmCodeEmail.Email)
 		return model.ErrOTPNotFound
 	}
@@ -145,26 +519,349 @@ func (a *authenticationService) ConfirmEmail(confirmCodeEmail model.ConfirmCodeE
 		return model.ErrInvalidOTP
 	}
 
-	if confirmationCode.Code != confirmCodeEmail.Code {
+	if confirmationCode.Attempts >= confirmationCodeMaxAttempts {
+		log.Warn("Too many wrong attempts for this token")
+		return a.invalidateConfirmationCode(confirmCodeEmail.Email)
+	}
+
+	if !isSameCode(confirmationCode.Code, confirmCodeEmail.Code) {
 		log.Warn("incorrect token")
+
+		attempts, err := a.confirmationCodeStore.IncrementAttempts(confirmCodeEmail.Email)
+		if err != nil {
+			log.Error("Error: " + err.Error())
+			return err
+		}
+
+		if attempts >= confirmationCodeMaxAttempts {
+			log.Warn("Too many wrong attempts for this token")
+			return a.invalidateConfirmationCode(confirmCodeEmail.Email)
+		}
+
 		return model.ErrInvalidOTP
 	}
 
-	if err := a.userRepository.UpdateConfirmedEmail(user.Id); err != nil {
-		log.Error("Error: ", err)
+	affiliation, err := a.emailDomainPolicy.Affiliation(user.Email)
+	if err != nil {
+		log.Warn("Email domain not allowed: " + user.Email)
 		return err
 	}
 
+	if err := a.userRepository.UpdateConfirmedEmail(user.Id, affiliation); err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	verifyMatricula(a.enrollmentVerifier, a.userRepository, *user, user.Email)
+
+	if err := a.confirmationCodeStore.Delete(confirmCodeEmail.Email); err != nil {
+		log.Warn("Error to delete used confirmation code: " + err.Error())
+	}
+
 	log.Info("Confirmed email successfully")
 	return nil
 }
 
-func (a *authenticationService) addOrUpdateConfirmationCode(email string, code model.ConfirmationCode) {
-	if existingCode, ok := confirmationsCodes[email]; ok {
-		existingCode.Code = code.Code
-		existingCode.ExpiryTime = code.ExpiryTime
-		confirmationsCodes[email] = existingCode
-	} else {
-		confirmationsCodes[email] = code
+func (a *authenticationService) SendPasswordResetCode(email string) error {
+	log := slog.With(
+		slog.String("func", "SendPasswordResetCode"),
+		slog.String("service", "authentication"))
+
+	email = normalizeEmail(email)
+
+	user, err := a.userRepository.GetByEmail(email)
+	if err != nil {
+		log.Warn("Failed to obtain user by email")
+		return model.ErrGetUser
+	}
+
+	if user == nil {
+		log.Warn("User not found with this email: " + email)
+		return nil
+	}
+
+	now := time.Now()
+	attempts := 0
+
+	resetCode, err := a.passwordResetCodeStore.Get(email)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrToSendPasswordResetCode
+	}
+
+	if resetCode != nil && now.Before(resetCode.ExpiryTime) {
+		if now.Sub(resetCode.LastSentAt) < passwordResetResendCooldown {
+			log.Warn("Password reset code resend throttled for email: " + email)
+			return nil
+		}
+
+		if resetCode.Attempts >= passwordResetCodeMaxAttempts {
+			log.Warn("Password reset code locked until it expires for email: " + email)
+			return nil
+		}
+
+		attempts = resetCode.Attempts
+	}
+
+	otp := model.PasswordResetCode{
+		Email:      email,
+		Code:       util.GenerateOTP(6),
+		ExpiryTime: now.Add(passwordResetCodeExpiry),
+		Attempts:   attempts,
+		LastSentAt: now,
+	}
+
+	if err := a.passwordResetCodeStore.Save(otp); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrToSendPasswordResetCode
+	}
+
+	subject := "Redefinição de senha"
+	content := fmt.Sprintf("<h1>Olá!</h1><p>Seu código para redefinir a senha é: <h2><b>%s</b></h2></p><p>O código expira em %d minutos.</p>", otp.Code, int(passwordResetCodeExpiry.Minutes()))
+	to := []string{email}
+
+	if err := a.emailService.SendEmail(subject, content, to); err != nil {
+		log.Error("Errors: " + err.Error())
+		return model.ErrToSendPasswordResetCode
 	}
+
+	log.Info("Password reset code send successfully")
+	return nil
+}
+
+func (a *authenticationService) SendMagicLink(email string) error {
+	log := slog.With(
+		slog.String("func", "SendMagicLink"),
+		slog.String("service", "authentication"))
+
+	if err := a.registerResend("magic_link:"+normalizeEmail(email), magicLinkResendCooldown, magicLinkDailyLimit); err != nil {
+		log.Warn("Magic link throttled for email: " + email)
+		return err
+	}
+
+	user, err := a.userRepository.GetByEmail(email)
+	if err != nil {
+		log.Warn("Failed to obtain user by email")
+		return model.ErrGetUser
+	}
+
+	if user == nil {
+		log.Warn("User not found with this email: " + email)
+		return nil
+	}
+
+	token, err := util.CreateMagicLinkToken(*user)
+	if err != nil {
+		log.Error("error trying create magic link token. Error: " + err.Error())
+		return model.ErrGenMagicLink
+	}
+
+	link := fmt.Sprintf("%s/auth/login/magic-link?token=%s", strings.TrimRight(config.FrontendURL, "/"), url.QueryEscape(token))
+
+	subject := "Seu link de acesso"
+	content := fmt.Sprintf("<h1>Olá!</h1><p>Clique no link abaixo para entrar na sua conta:</p><p><a href=\"%s\">Entrar</a></p><p>O link expira em %d minutos e só pode ser usado uma vez. Se não foi você que pediu, ignore este email.</p>", link, int(util.MagicLinkTokenTTL.Minutes()))
+	to := []string{email}
+
+	if err := a.emailService.SendEmail(subject, content, to); err != nil {
+		log.Error("Errors: " + err.Error())
+		return model.ErrToSendMagicLink
+	}
+
+	log.Info("Magic link send successfully")
+	return nil
+}
+
+func (a *authenticationService) MagicLinkLogin(token string, client model.ClientInfo) (*model.TokenPair, error) {
+	log := slog.With(
+		slog.String("func", "MagicLinkLogin"),
+		slog.String("service", "authentication"))
+
+	principal, err := util.ParseToken(token, util.TokenTypeMagicLink)
+	if err != nil {
+		log.Warn("Invalid magic link token")
+		return nil, model.ErrInvalidMagicLink
+	}
+
+	if err := a.CheckLoginThrottle(principal.Email, client.IP); err != nil {
+		log.Warn("Login throttled for email: " + principal.Email + " ip: " + client.IP)
+		return nil, err
+	}
+
+	revoked, err := a.tokenRevocationStore.Revoke(principal.TokenId, principal.ExpiresAt)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrRevokeToken
+	}
+
+	if !revoked {
+		log.Warn("Magic link already used: " + principal.TokenId)
+		return nil, model.ErrInvalidMagicLink
+	}
+
+	user, err := a.userRepository.GetById(principal.Id)
+	if err != nil {
+		log.Error("failed to get user by id")
+		return nil, model.ErrGetUser
+	}
+
+	if user == nil || user.Email != principal.Email {
+		log.Warn("Magic link does not match a current user: " + principal.Id)
+		return nil, model.ErrInvalidMagicLink
+	}
+
+	if !user.IsEmailConfirmed {
+		affiliation, err := a.emailDomainPolicy.Affiliation(user.Email)
+		if err != nil {
+			log.Warn("Email domain not allowed: " + user.Email)
+			return nil, err
+		}
+
+		if err := a.userRepository.UpdateConfirmedEmail(user.Id, affiliation); err != nil {
+			log.Error("Error: " + err.Error())
+			return nil, err
+		}
+		user.IsEmailConfirmed = true
+		user.Affiliation = affiliation
+
+		verifyMatricula(a.enrollmentVerifier, a.userRepository, *user, user.Email)
+	}
+
+	return a.CompleteLogin(*user, client, model.LoginMethodMagicLink, nil)
+}
+
+func (a *authenticationService) ResetPassword(resetPassword model.ResetPassword) error {
+	log := slog.With(
+		slog.String("func", "ResetPassword"),
+		slog.String("service", "authentication"))
+
+	email := normalizeEmail(resetPassword.Email)
+
+	if err := a.checkPasswordResetCode(email, resetPassword.Code); err != nil {
+		log.Warn("Invalid password reset code for email: " + email)
+		return err
+	}
+
+	user, err := a.userRepository.GetByEmail(email)
+	if err != nil {
+		log.Warn("Failed to obtain user by email")
+		return model.ErrGetUser
+	}
+
+	if user == nil {
+		log.Warn("User not found with this email: " + email)
+		return model.ErrUserNotFound
+	}
+
+	newHashedPassword, err := Hash(resetPassword.New)
+	if err != nil {
+		log.Error("Error trying to hashed password")
+		return model.ErrHashPassword
+	}
+
+	if err := a.userRepository.UpdatePassword(user.Id, string(newHashedPassword)); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrUpdatePassword
+	}
+
+	if err := a.revokeUserSessions(user.Id, ""); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrRevokeSession
+	}
+
+	log.Info("Password reset successfully")
+	return nil
+}
+
+func (a *authenticationService) checkPasswordResetCode(email string, code string) error {
+	resetCode, err := a.passwordResetCodeStore.Get(email)
+	if err != nil {
+		return err
+	}
+
+	if resetCode == nil || time.Now().After(resetCode.ExpiryTime) {
+		return model.ErrInvalidOTP
+	}
+
+	if resetCode.Attempts >= passwordResetCodeMaxAttempts {
+		return model.ErrOTPAttemptsExceeded
+	}
+
+	if !isSameCode(resetCode.Code, code) {
+		attempts, err := a.passwordResetCodeStore.IncrementAttempts(email)
+		if err != nil {
+			return err
+		}
+
+		if attempts >= passwordResetCodeMaxAttempts {
+			return model.ErrOTPAttemptsExceeded
+		}
+
+		return model.ErrInvalidOTP
+	}
+
+	return a.passwordResetCodeStore.Delete(email)
+}
+
+// revokeSession ends the session behind a refresh token family, so access
+// tokens already issued to it stop working along with the refresh tokens.
+func (a *authenticationService) revokeSession(sessionId string) error {
+	if err := a.sessionRepository.Revoke(sessionId); err != nil {
+		return err
+	}
+
+	return a.refreshTokenRepository.RevokeFamily(sessionId)
+}
+
+func (a *authenticationService) revokeUserSessions(userId string, exceptSessionId string) error {
+	if err := a.sessionRepository.RevokeAllForUser(userId, exceptSessionId); err != nil {
+		return err
+	}
+
+	return a.refreshTokenRepository.RevokeAllForUser(userId, exceptSessionId)
+}
+
+func (a *authenticationService) invalidateConfirmationCode(email string) error {
+	if err := a.confirmationCodeStore.Delete(email); err != nil {
+		return err
+	}
+
+	return model.ErrOTPAttemptsExceeded
+}
+
+// grantScopes returns the requested scopes when all of them are allowed, or
+// every allowed scope when none was requested.
+func grantScopes(allowed []string, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return allowed, nil
+	}
+
+	granted := uniqueScopes(requested)
+	for _, scope := range granted {
+		if !slices.Contains(allowed, scope) {
+			return nil, model.ErrScopeNotAllowed
+		}
+	}
+
+	return granted, nil
+}
+
+// keepAllowedScopes drops scopes the user lost since the session was opened,
+// for instance after an admin was demoted.
+func keepAllowedScopes(scopes []string, allowed []string) []string {
+	kept := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if slices.Contains(allowed, scope) {
+			kept = append(kept, scope)
+		}
+	}
+
+	return kept
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func isSameCode(expected string, received string) bool {
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(received)) == 1
 }
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OVillas/user-api/config"
+	"github.com/OVillas/user-api/mocks"
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/repository"
+	"github.com/OVillas/user-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testUserId = "6f1c2a8e-3b1d-4c5e-9f7a-2d4b6c8e0a12"
+
+func newTestConfirmationCodeStore(t *testing.T) model.ConfirmationCodeStore {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	return repository.NewInMemoryConfirmationCodeRepository(ctx, time.Hour)
+}
+
+func newConfirmEmailService(userRepository model.UserRepository, confirmationCodeStore model.ConfirmationCodeStore) *authenticationService {
+	return &authenticationService{
+		userRepository:        userRepository,
+		confirmationCodeStore: confirmationCodeStore,
+		emailDomainPolicy:     NewEmailDomainPolicy([]config.EmailDomainRule{{Domain: "uerj.br", Affiliation: model.AffiliationStaff}}, model.EmailDomainPolicyFlag),
+		enrollmentVerifier:    NewFakeEnrollmentVerifier(model.EnrollmentRecord{Matricula: "202310012345", Email: "ana@uerj.br"}),
+	}
+}
+
+func TestConfirmEmailRejectsExpiredCode(t *testing.T) {
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetByEmail", "ana@uerj.br").Return(&model.User{Id: testUserId, Email: "ana@uerj.br"}, nil)
+
+	store := newTestConfirmationCodeStore(t)
+	require.NoError(t, store.Save(model.ConfirmationCode{
+		Email:      "ana@uerj.br",
+		Code:       "123456",
+		ExpiryTime: time.Now().Add(-time.Second),
+	}))
+
+	a := newConfirmEmailService(userRepository, store)
+
+	err := a.ConfirmEmail(model.ConfirmCodeEmail{Email: "ana@uerj.br", Code: "123456"})
+	assert.ErrorIs(t, err, model.ErrInvalidOTP)
+}
+
+func TestConfirmEmailConsumesValidCode(t *testing.T) {
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetByEmail", "ana@uerj.br").Return(&model.User{Id: testUserId, Email: "ana@uerj.br"}, nil)
+	userRepository.On("UpdateConfirmedEmail", testUserId, model.AffiliationStaff).Return(nil).Once()
+
+	store := newTestConfirmationCodeStore(t)
+	require.NoError(t, store.Save(model.ConfirmationCode{
+		Email:      "ana@uerj.br",
+		Code:       "123456",
+		ExpiryTime: time.Now().Add(time.Hour),
+	}))
+
+	a := newConfirmEmailService(userRepository, store)
+
+	require.NoError(t, a.ConfirmEmail(model.ConfirmCodeEmail{Email: "ana@uerj.br", Code: "123456"}))
+
+	code, err := store.Get("ana@uerj.br")
+	require.NoError(t, err)
+	assert.Nil(t, code)
+}
+
+func TestConfirmEmailVerifiesMatriculaAgainstConfirmedEmail(t *testing.T) {
+	matricula := "202310012345"
+
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetByEmail", "ana@uerj.br").Return(&model.User{Id: testUserId, Email: "ana@uerj.br", Matricula: &matricula}, nil)
+	userRepository.On("UpdateConfirmedEmail", testUserId, model.AffiliationStaff).Return(nil).Once()
+	userRepository.On("UpdateMatriculaVerified", testUserId, true).Return(nil).Once()
+
+	store := newTestConfirmationCodeStore(t)
+	require.NoError(t, store.Save(model.ConfirmationCode{
+		Email:      "ana@uerj.br",
+		Code:       "123456",
+		ExpiryTime: time.Now().Add(time.Hour),
+	}))
+
+	a := newConfirmEmailService(userRepository, store)
+
+	require.NoError(t, a.ConfirmEmail(model.ConfirmCodeEmail{Email: "ana@uerj.br", Code: "123456"}))
+}
+
+func TestConfirmEmailInvalidatesCodeAfterMaxAttempts(t *testing.T) {
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetByEmail", "ana@uerj.br").Return(&model.User{Id: testUserId, Email: "ana@uerj.br"}, nil)
+
+	store := newTestConfirmationCodeStore(t)
+	require.NoError(t, store.Save(model.ConfirmationCode{
+		Email:      "ana@uerj.br",
+		Code:       "123456",
+		ExpiryTime: time.Now().Add(time.Hour),
+	}))
+
+	a := newConfirmEmailService(userRepository, store)
+	wrongCode := model.ConfirmCodeEmail{Email: "ana@uerj.br", Code: "000000"}
+
+	for attempt := 1; attempt < confirmationCodeMaxAttempts; attempt++ {
+		assert.ErrorIs(t, a.ConfirmEmail(wrongCode), model.ErrInvalidOTP)
+	}
+
+	assert.ErrorIs(t, a.ConfirmEmail(wrongCode), model.ErrOTPAttemptsExceeded)
+
+	// The right code is no longer accepted once the code was invalidated.
+	err := a.ConfirmEmail(model.ConfirmCodeEmail{Email: "ana@uerj.br", Code: "123456"})
+	assert.ErrorIs(t, err, model.ErrOTPNotFound)
+	userRepository.AssertNotCalled(t, "UpdateConfirmedEmail", testUserId, mock.Anything)
+}
+
+func TestRegisterConfirmationCodeResendEnforcesCooldown(t *testing.T) {
+	resendThrottleStore := mocks.NewResendThrottleStore(t)
+	resendThrottleStore.On("Get", "confirmation:ana@uerj.br").Return(&model.ResendThrottle{
+		Key:        "confirmation:ana@uerj.br",
+		LastSentAt: time.Now().Add(-10 * time.Second),
+		WindowEnd:  time.Now().Add(time.Hour),
+		Count:      1,
+	}, nil).Once()
+
+	a := &authenticationService{resendThrottleStore: resendThrottleStore}
+
+	err := a.registerConfirmationCodeResend(" Ana@UERJ.br ")
+	assert.ErrorIs(t, err, model.ErrResendCooldown)
+	resendThrottleStore.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestRegisterConfirmationCodeResendEnforcesDailyLimit(t *testing.T) {
+	resendThrottleStore := mocks.NewResendThrottleStore(t)
+	resendThrottleStore.On("Get", "confirmation:ana@uerj.br").Return(&model.ResendThrottle{
+		Key:        "confirmation:ana@uerj.br",
+		LastSentAt: time.Now().Add(-time.Hour),
+		WindowEnd:  time.Now().Add(time.Hour),
+		Count:      confirmationResendDailyLimit,
+	}, nil).Once()
+
+	a := &authenticationService{resendThrottleStore: resendThrottleStore}
+
+	assert.ErrorIs(t, a.registerConfirmationCodeResend("ana@uerj.br"), model.ErrResendDailyLimit)
+}
+
+func TestRegisterConfirmationCodeResendStartsNewWindow(t *testing.T) {
+	resendThrottleStore := mocks.NewResendThrottleStore(t)
+	resendThrottleStore.On("Get", "confirmation:ana@uerj.br").Return(&model.ResendThrottle{
+		Key:        "confirmation:ana@uerj.br",
+		LastSentAt: time.Now().Add(-25 * time.Hour),
+		WindowEnd:  time.Now().Add(-time.Hour),
+		Count:      confirmationResendDailyLimit,
+	}, nil).Once()
+	resendThrottleStore.On("Save", mock.MatchedBy(func(throttle model.ResendThrottle) bool {
+		return throttle.Key == "confirmation:ana@uerj.br" && throttle.Count == 1
+	})).Return(nil).Once()
+
+	a := &authenticationService{resendThrottleStore: resendThrottleStore}
+
+	assert.NoError(t, a.registerConfirmationCodeResend("ana@uerj.br"))
+}
+
+func TestUpdatePasswordRevokesOtherSessions(t *testing.T) {
+	hashedPassword, err := Hash("old-password")
+	require.NoError(t, err)
+
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetById", testUserId).Return(&model.User{Id: testUserId, Password: string(hashedPassword)}, nil).Once()
+	userRepository.On("UpdatePassword", testUserId, mock.Anything).Return(nil).Once()
+
+	sessionRepository := mocks.NewSessionRepository(t)
+	sessionRepository.On("RevokeAllForUser", testUserId, "current-session").Return(nil).Once()
+
+	refreshTokenRepository := mocks.NewRefreshTokenRepository(t)
+	refreshTokenRepository.On("RevokeAllForUser", testUserId, "current-session").Return(nil).Once()
+
+	a := &authenticationService{
+		userRepository:         userRepository,
+		sessionRepository:      sessionRepository,
+		refreshTokenRepository: refreshTokenRepository,
+	}
+
+	err = a.UpdatePassword(testUserId, "current-session", model.UpdatePassword{Current: "old-password", New: "new-password"})
+	assert.NoError(t, err)
+}
+
+func TestIssueTokensNarrowsScopesToRequest(t *testing.T) {
+	setupTokens(t)
+
+	sessionRepository := mocks.NewSessionRepository(t)
+	sessionRepository.On("Create", mock.MatchedBy(func(session model.Session) bool {
+		return session.Scopes == model.ScopeUserRead
+	})).Return(nil).Once()
+
+	refreshTokenRepository := mocks.NewRefreshTokenRepository(t)
+	refreshTokenRepository.On("Create", mock.Anything).Return(nil).Once()
+
+	loginHistoryService := mocks.NewLoginHistoryService(t)
+	loginHistoryService.On("Record", mock.Anything, mock.Anything, model.LoginMethodPassword, true).Return(nil).Once()
+
+	a := &authenticationService{
+		sessionRepository:      sessionRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		loginHistoryService:    loginHistoryService,
+	}
+
+	user := model.User{Id: testUserId, Role: model.RoleAdmin}
+	tokenPair, err := a.IssueTokens(user, model.ClientInfo{}, model.LoginMethodPassword, []string{model.ScopeUserRead})
+	require.NoError(t, err)
+
+	principal, err := util.ParseToken(tokenPair.AccessToken, util.TokenTypeAccess)
+	require.NoError(t, err)
+	assert.Equal(t, []string{model.ScopeUserRead}, principal.Scopes)
+}
+
+func TestIssueTokensRejectsScopeOutsideRole(t *testing.T) {
+	a := &authenticationService{sessionRepository: mocks.NewSessionRepository(t)}
+
+	_, err := a.IssueTokens(model.User{Id: testUserId}, model.ClientInfo{}, model.LoginMethodPassword, []string{model.ScopeAdmin})
+	assert.ErrorIs(t, err, model.ErrScopeNotAllowed)
+}
+
+func TestRefreshCannotWidenSessionScopes(t *testing.T) {
+	refreshTokenRepository := mocks.NewRefreshTokenRepository(t)
+	refreshTokenRepository.On("GetByTokenHash", util.HashToken("refresh-token")).Return(&model.RefreshToken{
+		Id:        "refresh-id",
+		UserId:    testUserId,
+		FamilyId:  "session-id",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil).Once()
+
+	sessionRepository := mocks.NewSessionRepository(t)
+	sessionRepository.On("GetById", "session-id").Return(&model.Session{
+		Id:        "session-id",
+		UserId:    testUserId,
+		Scopes:    model.ScopeUserRead,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil).Once()
+
+	a := &authenticationService{
+		sessionRepository:      sessionRepository,
+		refreshTokenRepository: refreshTokenRepository,
+	}
+
+	_, err := a.Refresh("refresh-token", []string{model.ScopeUserWrite})
+	assert.ErrorIs(t, err, model.ErrScopeNotAllowed)
+	refreshTokenRepository.AssertNotCalled(t, "MarkUsed", mock.Anything)
+}
+
+func TestRefreshReuseRevokesFamilyAndSession(t *testing.T) {
+	setupTokens(t)
+
+	var usedAt *time.Time
+	refreshTokenRepository := mocks.NewRefreshTokenRepository(t)
+	refreshTokenRepository.On("GetByTokenHash", util.HashToken("refresh-token")).Return(func(string) (*model.RefreshToken, error) {
+		return &model.RefreshToken{
+			Id:        "refresh-id",
+			UserId:    testUserId,
+			FamilyId:  "session-id",
+			UsedAt:    usedAt,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}, nil
+	}).Twice()
+	refreshTokenRepository.On("MarkUsed", "refresh-id").Run(func(mock.Arguments) {
+		now := time.Now()
+		usedAt = &now
+	}).Return(true, nil).Once()
+	refreshTokenRepository.On("Create", mock.Anything).Return(nil).Once()
+	refreshTokenRepository.On("RevokeFamily", "session-id").Return(nil).Once()
+
+	sessionRepository := mocks.NewSessionRepository(t)
+	sessionRepository.On("GetById", "session-id").Return(&model.Session{
+		Id:        "session-id",
+		UserId:    testUserId,
+		Scopes:    model.ScopeUserRead,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil).Once()
+	sessionRepository.On("Touch", "session-id", mock.Anything).Return(nil).Once()
+	sessionRepository.On("Revoke", "session-id").Return(nil).Once()
+
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetById", testUserId).Return(&model.User{Id: testUserId, Role: model.RoleUser}, nil).Once()
+
+	a := &authenticationService{
+		userRepository:         userRepository,
+		sessionRepository:      sessionRepository,
+		refreshTokenRepository: refreshTokenRepository,
+	}
+
+	_, err := a.Refresh("refresh-token", nil)
+	require.NoError(t, err)
+
+	_, err = a.Refresh("refresh-token", nil)
+	assert.ErrorIs(t, err, model.ErrRefreshTokenReused)
+}
+
+func TestMagicLinkLoginRejectsAlreadyConsumedLink(t *testing.T) {
+	setupTokens(t)
+
+	user := model.User{Id: testUserId, Email: "ana@uerj.br"}
+	token, err := util.CreateMagicLinkToken(user)
+	require.NoError(t, err)
+
+	principal, err := util.ParseToken(token, util.TokenTypeMagicLink)
+	require.NoError(t, err)
+
+	revocationStore := repository.NewInMemoryRevokedTokenRepository()
+	revoked, err := revocationStore.Revoke(principal.TokenId, principal.ExpiresAt)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	a := &authenticationService{
+		userRepository:       mocks.NewUserRepository(t),
+		tokenRevocationStore: revocationStore,
+		loginAttemptStore:    repository.NewInMemoryLoginAttemptRepository(time.Hour),
+	}
+
+	_, err = a.MagicLinkLogin(token, model.ClientInfo{})
+	assert.ErrorIs(t, err, model.ErrInvalidMagicLink)
+}
+
+func TestMagicLinkLoginRespectsAccountLockout(t *testing.T) {
+	setupTokens(t)
+
+	user := model.User{Id: testUserId, Email: "ana@uerj.br"}
+	token, err := util.CreateMagicLinkToken(user)
+	require.NoError(t, err)
+
+	loginAttemptStore := repository.NewInMemoryLoginAttemptRepository(time.Hour)
+	require.NoError(t, loginAttemptStore.Lock(loginEmailKey(user.Email), time.Now().Add(loginLockoutDuration)))
+
+	revocationStore := repository.NewInMemoryRevokedTokenRepository()
+	a := &authenticationService{
+		userRepository:       mocks.NewUserRepository(t),
+		tokenRevocationStore: revocationStore,
+		loginAttemptStore:    loginAttemptStore,
+	}
+
+	_, err = a.MagicLinkLogin(token, model.ClientInfo{IP: "200.20.10.4"})
+
+	var throttledErr *model.LoginThrottledError
+	require.ErrorAs(t, err, &throttledErr)
+
+	principal, err := util.ParseToken(token, util.TokenTypeMagicLink)
+	require.NoError(t, err)
+	revoked, err := revocationStore.IsRevoked(principal.TokenId)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestSendMagicLinkEnforcesCooldown(t *testing.T) {
+	resendThrottleStore := mocks.NewResendThrottleStore(t)
+	resendThrottleStore.On("Get", "magic_link:ana@uerj.br").Return(&model.ResendThrottle{
+		Key:        "magic_link:ana@uerj.br",
+		LastSentAt: time.Now(),
+		WindowEnd:  time.Now().Add(time.Hour),
+		Count:      1,
+	}, nil).Once()
+
+	a := &authenticationService{
+		userRepository:      mocks.NewUserRepository(t),
+		resendThrottleStore: resendThrottleStore,
+	}
+
+	assert.ErrorIs(t, a.SendMagicLink("Ana@uerj.br"), model.ErrResendCooldown)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"log/slog"
+	"strconv"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+)
+
+// The decoded avatar is held as RGBA, four bytes a pixel, so the pixel cap
+// keeps a single upload around 64 MiB of memory.
+const (
+	avatarMaxPixels   = 4096 * 4096
+	avatarJPEGQuality = 85
+)
+
+var avatarMimeTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+type avatarService struct {
+	userRepository model.UserRepository
+	blobStore      model.BlobStore
+}
+
+func NewAvatarService(userRepository model.UserRepository, blobStore model.BlobStore) model.AvatarService {
+	return &avatarService{
+		userRepository: userRepository,
+		blobStore:      blobStore,
+	}
+}
+
+func (a *avatarService) Upload(userId string, data []byte) (map[string]string, error) {
+	log := slog.With(
+		slog.String("func", "Upload"),
+		slog.String("service", "avatar"))
+
+	if len(data) > model.AvatarMaxSize {
+		log.Warn("Avatar exceeds the maximum size")
+		return nil, model.ErrAvatarTooLarge
+	}
+
+	mimeType := mimetype.Detect(data).String()
+	if !mimetype.EqualsAny(mimeType, avatarMimeTypes...) {
+		log.Warn("Unsupported avatar type: " + mimeType)
+		return nil, model.ErrInvalidAvatar
+	}
+
+	user, err := a.userRepository.GetById(userId)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrGetUser
+	}
+
+	if user == nil {
+		log.Warn("User not found to upload avatar")
+		return nil, model.ErrUserNotFound
+	}
+
+	imageConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		log.Warn("Error to decode avatar config: " + err.Error())
+		return nil, model.ErrInvalidAvatar
+	}
+
+	if imageConfig.Width*imageConfig.Height > avatarMaxPixels {
+		log.Warn("Avatar dimensions exceed the maximum")
+		return nil, model.ErrAvatarTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		log.Warn("Error to decode avatar: " + err.Error())
+		return nil, model.ErrInvalidAvatar
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrSaveAvatar
+	}
+	avatarId := id.String()
+
+	orientation := util.ExifOrientation(data)
+	src := util.ToRGBA(img)
+
+	// Every size is averaged straight from the source crop; scaling down an
+	// already resized thumbnail blurs the small avatars twice.
+	urls := map[string]string{}
+	for _, size := range model.AvatarSizes {
+		resized := util.SquareThumbnail(src, size)
+		resized = util.FlattenOnWhite(util.ApplyOrientation(resized, orientation))
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: avatarJPEGQuality}); err != nil {
+			log.Error("Error: " + err.Error())
+			return nil, model.ErrSaveAvatar
+		}
+
+		key := model.AvatarKey(userId, avatarId, size)
+		if err := a.blobStore.Put(key, "image/jpeg", buf.Bytes()); err != nil {
+			log.Error("Error: " + err.Error())
+			return nil, model.ErrSaveAvatar
+		}
+
+		urls[strconv.Itoa(size)] = a.blobStore.URL(key)
+	}
+
+	previousAvatarId := user.AvatarId
+	if err := a.userRepository.UpdateAvatar(userId, avatarId); err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrSaveAvatar
+	}
+
+	if previousAvatarId != nil {
+		for _, size := range model.AvatarSizes {
+			if err := a.blobStore.Delete(model.AvatarKey(userId, *previousAvatarId, size)); err != nil {
+				log.Warn("Error to delete previous avatar: " + err.Error())
+			}
+		}
+	}
+
+	log.Info("Avatar uploaded successfully")
+	return urls, nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/OVillas/user-api/mocks"
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testBlobBaseURL = "http://localhost/uploads/"
+
+type memoryBlobStore map[string][]byte
+
+func (mb memoryBlobStore) Put(key string, contentType string, data []byte) error {
+	mb[key] = data
+	return nil
+}
+
+func (mb memoryBlobStore) Delete(key string) error {
+	delete(mb, key)
+	return nil
+}
+
+func (mb memoryBlobStore) URL(key string) string {
+	return testBlobBaseURL + key
+}
+
+func newTestAvatarService(t *testing.T) (*avatarService, memoryBlobStore) {
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetById", testUserId).Return(&model.User{Id: testUserId}, nil).Maybe()
+	userRepository.On("UpdateAvatar", testUserId, mock.Anything).Return(nil).Maybe()
+
+	blobStore := memoryBlobStore{}
+	return &avatarService{userRepository: userRepository, blobStore: blobStore}, blobStore
+}
+
+// rotatedJPEG is a 40x20 photo, red on the left and blue on the right, whose
+// EXIF orientation 6 asks viewers to turn it 90 degrees clockwise.
+func rotatedJPEG(t *testing.T) []byte {
+	t.Helper()
+
+	src := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	draw.Draw(src, image.Rect(0, 0, 20, 20), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+	draw.Draw(src, image.Rect(20, 0, 40, 20), image.NewUniform(color.RGBA{B: 255, A: 255}), image.Point{}, draw.Src)
+
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, src, &jpeg.Options{Quality: 100}))
+	data := buf.Bytes()
+
+	tiff := []byte("MM\x00\x2A\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = append(tiff, 0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, 0x06, 0x00, 0x00)
+	tiff = binary.BigEndian.AppendUint32(tiff, 0)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := binary.BigEndian.AppendUint16([]byte{0xFF, 0xE1}, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+// pngHeader is a PNG that stops after its IHDR chunk, enough for
+// image.DecodeConfig to report the dimensions.
+func pngHeader(width uint32, height uint32) []byte {
+	ihdr := []byte("IHDR")
+	ihdr = binary.BigEndian.AppendUint32(ihdr, width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	ihdr = append(ihdr, 8, 6, 0, 0, 0)
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, uint32(len(ihdr)-4))
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+func storedAvatar(t *testing.T, blobStore memoryBlobStore, urls map[string]string, size int) []byte {
+	t.Helper()
+
+	data, ok := blobStore[strings.TrimPrefix(urls[strconv.Itoa(size)], testBlobBaseURL)]
+	require.True(t, ok)
+	return data
+}
+
+func assertColor(t *testing.T, expected color.RGBA, actual color.Color) {
+	t.Helper()
+
+	r, g, b, _ := actual.RGBA()
+	assert.InDelta(t, expected.R, r>>8, 16)
+	assert.InDelta(t, expected.G, g>>8, 16)
+	assert.InDelta(t, expected.B, b>>8, 16)
+}
+
+func TestUploadAvatarAppliesOrientationAndStripsExif(t *testing.T) {
+	a, blobStore := newTestAvatarService(t)
+
+	urls, err := a.Upload(testUserId, rotatedJPEG(t))
+	require.NoError(t, err)
+
+	for _, size := range model.AvatarSizes {
+		data := storedAvatar(t, blobStore, urls, size)
+		assert.NotContains(t, string(data), "Exif")
+		assert.Equal(t, 1, util.ExifOrientation(data))
+
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, image.Pt(size, size), img.Bounds().Size())
+
+		// Turned clockwise, the red left half of the crop ends up on top.
+		assertColor(t, color.RGBA{R: 255}, img.At(size/2, size/8))
+		assertColor(t, color.RGBA{B: 255}, img.At(size/2, size-1-size/8))
+	}
+}
+
+func TestUploadAvatarFlattensTransparentPNG(t *testing.T) {
+	a, blobStore := newTestAvatarService(t)
+
+	src := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	draw.Draw(src, image.Rect(0, 16, 32, 32), image.NewUniform(color.NRGBA{G: 255, A: 255}), image.Point{}, draw.Src)
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, src))
+
+	urls, err := a.Upload(testUserId, buf.Bytes())
+	require.NoError(t, err)
+
+	img, err := jpeg.Decode(bytes.NewReader(storedAvatar(t, blobStore, urls, 64)))
+	require.NoError(t, err)
+
+	assertColor(t, color.RGBA{R: 255, G: 255, B: 255}, img.At(32, 8))
+	assertColor(t, color.RGBA{G: 255}, img.At(32, 56))
+}
+
+func TestUploadAvatarRejectsTooManyPixels(t *testing.T) {
+	testCases := []struct {
+		name   string
+		width  uint32
+		height uint32
+	}{
+		{name: "above the limit", width: 4097, height: 4096},
+		{name: "decompression bomb", width: 50_000, height: 50_000},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			a, blobStore := newTestAvatarService(t)
+
+			_, err := a.Upload(testUserId, pngHeader(testCase.width, testCase.height))
+
+			assert.ErrorIs(t, err, model.ErrAvatarTooLarge)
+			assert.Empty(t, blobStore)
+		})
+	}
+}
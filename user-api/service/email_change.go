@@ -0,0 +1,211 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/OVillas/user-api/config"
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+)
+
+const (
+	emailChangeExpiry         = time.Hour
+	emailChangeMaxAttempts    = 5
+	emailChangeResendCooldown = time.Minute
+)
+
+func (us userService) checkEmailChange(user model.User, newEmail string) (int, error) {
+	log := slog.With(
+		slog.String("service", "user"),
+		slog.String("func", "checkEmailChange"))
+
+	if _, err := us.emailDomainPolicy.Affiliation(newEmail); err != nil {
+		log.Warn("Email domain not allowed: " + newEmail)
+		return 0, err
+	}
+
+	registeredUser, err := us.userRepository.GetByEmail(newEmail)
+	if err != nil {
+		log.Error("Error trying to get user from repository")
+		return 0, model.ErrGetUser
+	}
+
+	if registeredUser != nil {
+		log.Warn("There is already a registered user with this email: " + newEmail)
+		return 0, model.ErrUserAlreadyRegistered
+	}
+
+	// A new request replaces the code but keeps the wrong attempts of the
+	// pending one, otherwise re-requesting would reset the guess limit.
+	pendingRequest, err := us.emailChangeRepository.GetByUserId(user.Id)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return 0, err
+	}
+
+	if pendingRequest == nil || time.Now().After(pendingRequest.ExpiresAt) {
+		return 0, nil
+	}
+
+	if pendingRequest.Attempts >= emailChangeMaxAttempts {
+		log.Warn("Email change attempts exceeded for user: " + user.Id)
+		return 0, model.ErrEmailChangeAttemptsExceeded
+	}
+
+	if time.Since(pendingRequest.CreatedAt) < emailChangeResendCooldown {
+		log.Warn("Email change requested too soon for user: " + user.Id)
+		return 0, model.ErrResendCooldown
+	}
+
+	return pendingRequest.Attempts, nil
+}
+
+func (us userService) requestEmailChange(user model.User, newEmail string, attempts int) error {
+	log := slog.With(
+		slog.String("service", "user"),
+		slog.String("func", "requestEmailChange"))
+
+	cancelToken, err := util.GenerateRandomToken(32)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrToSendEmailChange
+	}
+
+	code := util.GenerateOTP(6)
+	err = us.emailChangeRepository.Save(model.EmailChangeRequest{
+		UserId:          user.Id,
+		NewEmail:        newEmail,
+		CodeHash:        util.HashToken(code),
+		CancelTokenHash: util.HashToken(cancelToken),
+		Attempts:        attempts,
+		ExpiresAt:       time.Now().Add(emailChangeExpiry),
+	})
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrToSendEmailChange
+	}
+
+	subject := "Confirmação de novo e-mail"
+	content := fmt.Sprintf("<h1>Olá!</h1><p>Use o código abaixo para confirmar este endereço como o novo e-mail da sua conta:</p><h2><b>%s</b></h2><p>O código expira em %d minutos.</p>", code, int(emailChangeExpiry.Minutes()))
+	if err := us.emailService.SendEmail(subject, content, []string{newEmail}); err != nil {
+		log.Error("Errors: " + err.Error())
+		return model.ErrToSendEmailChange
+	}
+
+	cancelLink := fmt.Sprintf("%s/auth/email-change/cancel?token=%s", strings.TrimRight(config.FrontendURL, "/"), url.QueryEscape(cancelToken))
+	subject = "Seu e-mail está sendo alterado"
+	content = fmt.Sprintf("<h1>Olá!</h1><p>Recebemos um pedido para trocar o e-mail da sua conta para <b>%s</b>.</p><p>Se não foi você, <a href=\"%s\">cancele a alteração</a> e redefina sua senha.</p>", newEmail, cancelLink)
+	if err := us.emailService.SendEmail(subject, content, []string{user.Email}); err != nil {
+		log.Error("Errors: " + err.Error())
+		return model.ErrToSendEmailChange
+	}
+
+	log.Info("Email change requested successfully")
+	return nil
+}
+
+func (us userService) ConfirmEmailChange(id string, code string) error {
+	log := slog.With(
+		slog.String("service", "user"),
+		slog.String("func", "ConfirmEmailChange"))
+
+	emailChangeRequest, err := us.emailChangeRepository.GetByUserId(id)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	if emailChangeRequest == nil {
+		log.Warn("No pending email change for user: " + id)
+		return model.ErrEmailChangeNotFound
+	}
+
+	if time.Now().After(emailChangeRequest.ExpiresAt) {
+		log.Warn("Email change expired for user: " + id)
+		if err := us.emailChangeRepository.Delete(id); err != nil {
+			log.Warn("Error to delete expired email change: " + err.Error())
+		}
+		return model.ErrInvalidEmailChangeCode
+	}
+
+	if emailChangeRequest.Attempts >= emailChangeMaxAttempts {
+		log.Warn("Email change attempts exceeded for user: " + id)
+		return model.ErrEmailChangeAttemptsExceeded
+	}
+
+	if !isSameCode(emailChangeRequest.CodeHash, util.HashToken(code)) {
+		if _, err := us.emailChangeRepository.IncrementAttempts(id); err != nil {
+			log.Error("Error: " + err.Error())
+		}
+		log.Warn("Wrong email change code for user: " + id)
+		return model.ErrInvalidEmailChangeCode
+	}
+
+	registeredUser, err := us.userRepository.GetByEmail(emailChangeRequest.NewEmail)
+	if err != nil {
+		log.Error("Error trying to get user from repository")
+		return model.ErrGetUser
+	}
+
+	if registeredUser != nil {
+		log.Warn("There is already a registered user with this email: " + emailChangeRequest.NewEmail)
+		return model.ErrUserAlreadyRegistered
+	}
+
+	affiliation, err := us.emailDomainPolicy.Affiliation(emailChangeRequest.NewEmail)
+	if err != nil {
+		log.Warn("Email domain not allowed: " + emailChangeRequest.NewEmail)
+		return err
+	}
+
+	if err := us.userRepository.UpdateEmail(id, emailChangeRequest.NewEmail, affiliation); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrUpdateEmail
+	}
+
+	// The matricula is checked again against the address just confirmed.
+	user, err := us.userRepository.GetById(id)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+	}
+
+	if user != nil {
+		verifyMatricula(us.enrollmentVerifier, us.userRepository, *user, emailChangeRequest.NewEmail)
+	}
+
+	if err := us.emailChangeRepository.Delete(id); err != nil {
+		log.Warn("Error to delete confirmed email change: " + err.Error())
+	}
+
+	log.Info("Email changed successfully")
+	return nil
+}
+
+func (us userService) CancelEmailChange(token string) error {
+	log := slog.With(
+		slog.String("service", "user"),
+		slog.String("func", "CancelEmailChange"))
+
+	emailChangeRequest, err := us.emailChangeRepository.GetByCancelTokenHash(util.HashToken(token))
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	if emailChangeRequest == nil {
+		log.Warn("No pending email change for this cancel token")
+		return model.ErrEmailChangeNotFound
+	}
+
+	if err := us.emailChangeRepository.Delete(emailChangeRequest.UserId); err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("Email change canceled successfully")
+	return nil
+}
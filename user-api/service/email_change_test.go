@@ -0,0 +1,107 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OVillas/user-api/config"
+	"github.com/OVillas/user-api/mocks"
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newEmailChangeService(t *testing.T, emailChangeRepository model.EmailChangeRepository, emailService model.EmailService) userService {
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetById", testUserId).Return(&model.User{Id: testUserId, Email: "old@uerj.br"}, nil).Maybe()
+	userRepository.On("GetByEmail", "new@uerj.br").Return(nil, nil)
+
+	return userService{
+		userRepository:        userRepository,
+		emailChangeRepository: emailChangeRepository,
+		emailService:          emailService,
+		emailDomainPolicy:     NewEmailDomainPolicy([]config.EmailDomainRule{{Domain: "uerj.br", Affiliation: model.AffiliationStaff}}, model.EmailDomainPolicyFlag),
+	}
+}
+
+func TestRequestEmailChangeKeepsPendingAttempts(t *testing.T) {
+	emailChangeRepository := mocks.NewEmailChangeRepository(t)
+	emailChangeRepository.On("GetByUserId", testUserId).Return(&model.EmailChangeRequest{
+		UserId:    testUserId,
+		NewEmail:  "new@uerj.br",
+		Attempts:  3,
+		ExpiresAt: time.Now().Add(30 * time.Minute),
+		CreatedAt: time.Now().Add(-30 * time.Minute),
+	}, nil).Once()
+	emailChangeRepository.On("Save", mock.MatchedBy(func(emailChangeRequest model.EmailChangeRequest) bool {
+		return emailChangeRequest.Attempts == 3
+	})).Return(nil).Once()
+
+	emailService := mocks.NewEmailService(t)
+	emailService.On("SendEmail", mock.Anything, mock.Anything, mock.Anything).Return(nil).Twice()
+
+	us := newEmailChangeService(t, emailChangeRepository, emailService)
+
+	assert.NoError(t, us.Update(testUserId, model.UserUpdatePayLoad{Email: "new@uerj.br"}))
+}
+
+func TestRequestEmailChangeRejectsExhaustedRequest(t *testing.T) {
+	emailChangeRepository := mocks.NewEmailChangeRepository(t)
+	emailChangeRepository.On("GetByUserId", testUserId).Return(&model.EmailChangeRequest{
+		UserId:    testUserId,
+		NewEmail:  "new@uerj.br",
+		Attempts:  emailChangeMaxAttempts,
+		ExpiresAt: time.Now().Add(30 * time.Minute),
+		CreatedAt: time.Now().Add(-30 * time.Minute),
+	}, nil).Once()
+
+	us := newEmailChangeService(t, emailChangeRepository, mocks.NewEmailService(t))
+
+	_, err := us.checkEmailChange(model.User{Id: testUserId, Email: "old@uerj.br"}, "new@uerj.br")
+	assert.ErrorIs(t, err, model.ErrEmailChangeAttemptsExceeded)
+	emailChangeRepository.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestRequestEmailChangeEnforcesCooldown(t *testing.T) {
+	emailChangeRepository := mocks.NewEmailChangeRepository(t)
+	emailChangeRepository.On("GetByUserId", testUserId).Return(&model.EmailChangeRequest{
+		UserId:    testUserId,
+		NewEmail:  "new@uerj.br",
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}, nil).Once()
+
+	us := newEmailChangeService(t, emailChangeRepository, mocks.NewEmailService(t))
+
+	_, err := us.checkEmailChange(model.User{Id: testUserId, Email: "old@uerj.br"}, "new@uerj.br")
+	assert.ErrorIs(t, err, model.ErrResendCooldown)
+}
+
+func TestConfirmEmailChangeVerifiesMatriculaAgainstNewEmail(t *testing.T) {
+	matricula := "202310012345"
+
+	emailChangeRepository := mocks.NewEmailChangeRepository(t)
+	emailChangeRepository.On("GetByUserId", testUserId).Return(&model.EmailChangeRequest{
+		UserId:    testUserId,
+		NewEmail:  "new@uerj.br",
+		CodeHash:  util.HashToken("123456"),
+		ExpiresAt: time.Now().Add(30 * time.Minute),
+	}, nil).Once()
+	emailChangeRepository.On("Delete", testUserId).Return(nil).Once()
+
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetByEmail", "new@uerj.br").Return(nil, nil).Once()
+	userRepository.On("UpdateEmail", testUserId, "new@uerj.br", model.AffiliationStaff).Return(nil).Once()
+	userRepository.On("GetById", testUserId).Return(&model.User{Id: testUserId, Email: "new@uerj.br", Matricula: &matricula, IsEmailConfirmed: true}, nil).Once()
+	userRepository.On("UpdateMatriculaVerified", testUserId, true).Return(nil).Once()
+
+	us := userService{
+		userRepository:        userRepository,
+		emailChangeRepository: emailChangeRepository,
+		emailDomainPolicy:     NewEmailDomainPolicy([]config.EmailDomainRule{{Domain: "uerj.br", Affiliation: model.AffiliationStaff}}, model.EmailDomainPolicyFlag),
+		enrollmentVerifier:    NewFakeEnrollmentVerifier(model.EnrollmentRecord{Matricula: matricula, Email: "new@uerj.br"}),
+	}
+
+	assert.NoError(t, us.ConfirmEmailChange(testUserId, "123456"))
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/OVillas/user-api/config"
+	"github.com/OVillas/user-api/model"
+)
+
+type emailDomainPolicy struct {
+	rules []config.EmailDomainRule
+	mode  string
+}
+
+func NewEmailDomainPolicy(rules []config.EmailDomainRule, mode string) model.EmailDomainPolicy {
+	return &emailDomainPolicy{
+		rules: rules,
+		mode:  mode,
+	}
+}
+
+func (ep *emailDomainPolicy) Affiliation(email string) (string, error) {
+	log := slog.With(
+		slog.String("func", "Affiliation"),
+		slog.String("service", "emailDomainPolicy"))
+
+	domain := strings.ToLower(email[strings.LastIndex(email, "@")+1:])
+
+	matched := config.EmailDomainRule{}
+	for _, rule := range ep.rules {
+		if domain != rule.Domain && !strings.HasSuffix(domain, "."+rule.Domain) {
+			continue
+		}
+
+		if len(rule.Domain) > len(matched.Domain) {
+			matched = rule
+		}
+	}
+
+	if matched.Domain != "" {
+		return matched.Affiliation, nil
+	}
+
+	if ep.mode == model.EmailDomainPolicyReject {
+		log.Warn("Email domain rejected: " + domain)
+		return "", model.ErrEmailDomainNotAllowed
+	}
+
+	log.Warn("Email domain flagged as external: " + domain)
+	return model.AffiliationExternal, nil
+}
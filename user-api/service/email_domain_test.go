@@ -0,0 +1,46 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/OVillas/user-api/config"
+	"github.com/OVillas/user-api/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var testEmailDomainRules = []config.EmailDomainRule{
+	{Domain: "uerj.br", Affiliation: model.AffiliationStaff},
+	{Domain: "graduacao.uerj.br", Affiliation: model.AffiliationStudent},
+	{Domain: "pos.uerj.br", Affiliation: model.AffiliationStudent},
+}
+
+func TestEmailDomainPolicyAffiliation(t *testing.T) {
+	testCases := []struct {
+		name        string
+		email       string
+		mode        string
+		affiliation string
+		err         error
+	}{
+		{name: "staff domain", email: "ana@uerj.br", mode: model.EmailDomainPolicyReject, affiliation: model.AffiliationStaff},
+		{name: "longest rule wins", email: "ana@graduacao.uerj.br", mode: model.EmailDomainPolicyReject, affiliation: model.AffiliationStudent},
+		{name: "unlisted subdomain", email: "ana@ime.uerj.br", mode: model.EmailDomainPolicyReject, affiliation: model.AffiliationStaff},
+		{name: "mixed case", email: "Ana@GRADUACAO.Uerj.BR", mode: model.EmailDomainPolicyReject, affiliation: model.AffiliationStudent},
+		{name: "lookalike rejected", email: "ana@eviluerj.br", mode: model.EmailDomainPolicyReject, err: model.ErrEmailDomainNotAllowed},
+		{name: "lookalike subdomain rejected", email: "ana@graduacao.eviluerj.br", mode: model.EmailDomainPolicyReject, err: model.ErrEmailDomainNotAllowed},
+		{name: "suffix in local part rejected", email: "ana.uerj.br@gmail.com", mode: model.EmailDomainPolicyReject, err: model.ErrEmailDomainNotAllowed},
+		{name: "lookalike flagged", email: "ana@eviluerj.br", mode: model.EmailDomainPolicyFlag, affiliation: model.AffiliationExternal},
+		{name: "external flagged", email: "ana@gmail.com", mode: model.EmailDomainPolicyFlag, affiliation: model.AffiliationExternal},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			policy := NewEmailDomainPolicy(testEmailDomainRules, testCase.mode)
+
+			affiliation, err := policy.Affiliation(testCase.email)
+
+			assert.ErrorIs(t, err, testCase.err)
+			assert.Equal(t, testCase.affiliation, affiliation)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type csvEnrollmentVerifier struct {
+	path    string
+	mu      sync.RWMutex
+	modTime time.Time
+	records map[string]model.EnrollmentRecord
+}
+
+func NewCSVEnrollmentVerifier(path string) (model.EnrollmentVerifier, error) {
+	ev := &csvEnrollmentVerifier{
+		path: path,
+	}
+
+	if err := ev.load(); err != nil {
+		return nil, err
+	}
+
+	return ev, nil
+}
+
+func (ev *csvEnrollmentVerifier) Verify(matricula string, email string) (bool, error) {
+	log := slog.With(
+		slog.String("func", "Verify"),
+		slog.String("service", "csvEnrollmentVerifier"))
+
+	if err := ev.load(); err != nil {
+		log.Error("Error: " + err.Error())
+		return false, model.ErrVerifyEnrollment
+	}
+
+	ev.mu.RLock()
+	defer ev.mu.RUnlock()
+
+	record, ok := ev.records[matricula]
+	return ok && matchesEnrollmentRecord(record, email), nil
+}
+
+func (ev *csvEnrollmentVerifier) load() error {
+	info, err := os.Stat(ev.path)
+	if err != nil {
+		return err
+	}
+
+	ev.mu.RLock()
+	upToDate := ev.records != nil && info.ModTime().Equal(ev.modTime)
+	ev.mu.RUnlock()
+	if upToDate {
+		return nil
+	}
+
+	file, err := os.Open(ev.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+
+	columns := map[string]int{"matricula": -1, "email": -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if column, ok := columns[name]; ok && column < 0 {
+			columns[name] = i
+		}
+	}
+
+	if columns["matricula"] < 0 {
+		return errors.New("enrollment csv has no matricula column: " + ev.path)
+	}
+
+	if columns["email"] < 0 {
+		return errors.New("enrollment csv has no email column: " + ev.path)
+	}
+
+	field := func(record []string, name string) string {
+		column := columns[name]
+		if column < 0 || column >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[column])
+	}
+
+	records := map[string]model.EnrollmentRecord{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if matricula := field(record, "matricula"); matricula != "" {
+			records[matricula] = model.EnrollmentRecord{
+				Matricula: matricula,
+				Email:     field(record, "email"),
+			}
+		}
+	}
+
+	ev.mu.Lock()
+	ev.records = records
+	ev.modTime = info.ModTime()
+	ev.mu.Unlock()
+
+	slog.Info("enrollment csv loaded", slog.Int("matriculas", len(records)))
+	return nil
+}
+
+type fakeEnrollmentVerifier struct {
+	records map[string]model.EnrollmentRecord
+}
+
+func NewFakeEnrollmentVerifier(records ...model.EnrollmentRecord) model.EnrollmentVerifier {
+	fv := fakeEnrollmentVerifier{
+		records: map[string]model.EnrollmentRecord{},
+	}
+
+	for _, record := range records {
+		fv.records[record.Matricula] = record
+	}
+
+	return fv
+}
+
+func (fv fakeEnrollmentVerifier) Verify(matricula string, email string) (bool, error) {
+	record, ok := fv.records[matricula]
+	return ok && matchesEnrollmentRecord(record, email), nil
+}
+
+// disabledEnrollmentVerifier is used while no registrar export is available:
+// matriculas are still stored, they just never count as verified.
+type disabledEnrollmentVerifier struct{}
+
+func NewDisabledEnrollmentVerifier() model.EnrollmentVerifier {
+	return disabledEnrollmentVerifier{}
+}
+
+func (disabledEnrollmentVerifier) Verify(matricula string, email string) (bool, error) {
+	return false, nil
+}
+
+func matchesEnrollmentRecord(record model.EnrollmentRecord, email string) bool {
+	return record.Email != "" && email != "" && normalizeEmail(record.Email) == normalizeEmail(email)
+}
+
+// verifyMatricula stores whether the registrar lists the user's matricula
+// under email. It runs once the email is confirmed, the name is never
+// compared since anyone can type a classmate's name. When the verifier fails
+// the matricula stays unverified instead of failing the confirmation.
+func verifyMatricula(enrollmentVerifier model.EnrollmentVerifier, userRepository model.UserRepository, user model.User, email string) {
+	log := slog.With(
+		slog.String("func", "verifyMatricula"),
+		slog.String("service", "enrollmentVerifier"))
+
+	if user.Matricula == nil {
+		return
+	}
+
+	verified, err := enrollmentVerifier.Verify(*user.Matricula, email)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		verified = false
+	}
+
+	if verified == user.MatriculaVerified {
+		return
+	}
+
+	if err := userRepository.UpdateMatriculaVerified(user.Id, verified); err != nil {
+		log.Error("Error: " + err.Error())
+		return
+	}
+
+	log.Info("Matricula verification updated for user: " + user.Id)
+}
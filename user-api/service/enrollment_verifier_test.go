@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OVillas/user-api/mocks"
+	"github.com/OVillas/user-api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeEnrollmentVerifierMatchesEmailOnly(t *testing.T) {
+	ev := NewFakeEnrollmentVerifier(model.EnrollmentRecord{Matricula: "202310012345", Email: "ana@graduacao.uerj.br"})
+
+	for _, tc := range []struct {
+		name      string
+		matricula string
+		email     string
+		verified  bool
+	}{
+		{"email matches", "202310012345", "ANA@graduacao.uerj.br", true},
+		{"email differs", "202310012345", "outra@uerj.br", false},
+		{"no email", "202310012345", "", false},
+		{"unknown matricula", "202310099999", "ana@graduacao.uerj.br", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			verified, err := ev.Verify(tc.matricula, tc.email)
+			require.NoError(t, err)
+			assert.Equal(t, tc.verified, verified)
+		})
+	}
+}
+
+func TestDisabledEnrollmentVerifierNeverVerifies(t *testing.T) {
+	verified, err := NewDisabledEnrollmentVerifier().Verify("202310012345", "ana@graduacao.uerj.br")
+	require.NoError(t, err)
+	assert.False(t, verified)
+}
+
+func TestCSVEnrollmentVerifierMatchesRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "matriculas.csv")
+	require.NoError(t, os.WriteFile(path, []byte("Matricula,Nome,Email\n202310012345,Ana Souza,ana@graduacao.uerj.br\n202310054321,Bruno Lima,\n"), 0o600))
+
+	ev, err := NewCSVEnrollmentVerifier(path)
+	require.NoError(t, err)
+
+	verified, err := ev.Verify("202310012345", "ana@graduacao.uerj.br")
+	require.NoError(t, err)
+	assert.True(t, verified)
+
+	verified, err = ev.Verify("202310054321", "bruno@uerj.br")
+	require.NoError(t, err)
+	assert.False(t, verified)
+
+	verified, err = ev.Verify("202310054321", "ana@graduacao.uerj.br")
+	require.NoError(t, err)
+	assert.False(t, verified)
+}
+
+func TestCSVEnrollmentVerifierRequiresEmailColumn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "matriculas.csv")
+	require.NoError(t, os.WriteFile(path, []byte("matricula,nome\n202310012345,Ana Souza\n"), 0o600))
+
+	_, err := NewCSVEnrollmentVerifier(path)
+	assert.Error(t, err)
+}
+
+func TestCSVEnrollmentVerifierFailsOnMissingFile(t *testing.T) {
+	_, err := NewCSVEnrollmentVerifier(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.Error(t, err)
+}
+
+type failingEnrollmentVerifier struct{}
+
+func (failingEnrollmentVerifier) Verify(matricula string, email string) (bool, error) {
+	return false, errors.New("registrar export unreadable")
+}
+
+func TestVerifyMatricula(t *testing.T) {
+	matricula := "202310012345"
+	verifier := NewFakeEnrollmentVerifier(model.EnrollmentRecord{Matricula: matricula, Email: "ana@uerj.br"})
+
+	testCases := []struct {
+		name     string
+		verifier model.EnrollmentVerifier
+		user     model.User
+		email    string
+		stored   bool
+		verified bool
+	}{
+		{name: "no matricula", verifier: verifier, user: model.User{Id: testUserId}, email: "ana@uerj.br"},
+		{name: "verified", verifier: verifier, user: model.User{Id: testUserId, Matricula: &matricula}, email: "ana@uerj.br", stored: true, verified: true},
+		{name: "already verified", verifier: verifier, user: model.User{Id: testUserId, Matricula: &matricula, MatriculaVerified: true}, email: "ana@uerj.br"},
+		{name: "new email not in records", verifier: verifier, user: model.User{Id: testUserId, Matricula: &matricula, MatriculaVerified: true}, email: "ana@gmail.com", stored: true},
+		{name: "verifier error", verifier: failingEnrollmentVerifier{}, user: model.User{Id: testUserId, Matricula: &matricula, MatriculaVerified: true}, email: "ana@uerj.br", stored: true},
+		{name: "disabled", verifier: NewDisabledEnrollmentVerifier(), user: model.User{Id: testUserId, Matricula: &matricula}, email: "ana@uerj.br"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			userRepository := mocks.NewUserRepository(t)
+			if testCase.stored {
+				userRepository.On("UpdateMatriculaVerified", testUserId, testCase.verified).Return(nil).Once()
+			}
+
+			verifyMatricula(testCase.verifier, userRepository, testCase.user, testCase.email)
+		})
+	}
+}
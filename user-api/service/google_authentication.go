@@ -0,0 +1,229 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+)
+
+const (
+	oidcStateExpiry   = 10 * time.Minute
+	userNameMaxLength = 70
+)
+
+type googleAuthenticationService struct {
+	userRepository        model.UserRepository
+	authenticationService model.AuthenticationService
+	oidcProvider          model.OIDCProvider
+	emailDomainPolicy     model.EmailDomainPolicy
+}
+
+func NewGoogleAuthenticationService(
+	userRepository model.UserRepository,
+	authenticationService model.AuthenticationService,
+	oidcProvider model.OIDCProvider,
+	emailDomainPolicy model.EmailDomainPolicy,
+) model.GoogleAuthenticationService {
+	return &googleAuthenticationService{
+		userRepository:        userRepository,
+		authenticationService: authenticationService,
+		oidcProvider:          oidcProvider,
+		emailDomainPolicy:     emailDomainPolicy,
+	}
+}
+
+func (g *googleAuthenticationService) Authorize() (*model.OIDCAuthorization, error) {
+	log := slog.With(
+		slog.String("func", "Authorize"),
+		slog.String("service", "googleAuthentication"))
+
+	state, err := util.GenerateRandomToken(32)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrOIDCGenState
+	}
+
+	nonce, err := util.GenerateRandomToken(32)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrOIDCGenState
+	}
+
+	codeVerifier, err := util.GenerateRandomToken(32)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrOIDCGenState
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	codeChallenge := base64.RawURLEncoding.EncodeToString(challenge[:])
+
+	authorizationURL, err := g.oidcProvider.AuthorizationURL(state, nonce, codeChallenge)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	expiryTime := time.Now().Add(oidcStateExpiry)
+	stateToken, err := util.CreateOIDCStateToken(model.OIDCState{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiryTime:   expiryTime,
+	})
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrOIDCGenState
+	}
+
+	log.Info("Authorize executed successfully")
+	return &model.OIDCAuthorization{
+		AuthorizationURL: authorizationURL,
+		StateToken:       stateToken,
+		ExpiryTime:       expiryTime,
+	}, nil
+}
+
+// Callback only accepts a state that matches the state token stored in the
+// cookie of the browser that called Authorize, so a code obtained in another
+// browser cannot be used to log this one in.
+func (g *googleAuthenticationService) Callback(oidcCallback model.OIDCCallback, stateToken string, client model.ClientInfo) (*model.TokenPair, error) {
+	log := slog.With(
+		slog.String("func", "Callback"),
+		slog.String("service", "googleAuthentication"))
+
+	state, err := util.ParseOIDCStateToken(stateToken)
+	if err != nil {
+		log.Warn("Missing or expired oidc state cookie")
+		return nil, model.ErrOIDCInvalidState
+	}
+
+	if subtle.ConstantTimeCompare([]byte(state.State), []byte(oidcCallback.State)) != 1 {
+		log.Warn("Oidc state does not match the state cookie")
+		return nil, model.ErrOIDCInvalidState
+	}
+
+	rawIDToken, err := g.oidcProvider.Exchange(oidcCallback.Code, state.CodeVerifier)
+	if err != nil {
+		log.Warn("Error: " + err.Error())
+		return nil, err
+	}
+
+	claims, err := g.oidcProvider.VerifyIDToken(rawIDToken, state.Nonce)
+	if err != nil {
+		log.Warn("Error: " + err.Error())
+		return nil, err
+	}
+
+	user, err := g.linkOrCreateUser(*claims)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Google login executed successfully")
+	return g.authenticationService.CompleteLogin(*user, client, model.LoginMethodGoogle, nil)
+}
+
+func (g *googleAuthenticationService) linkOrCreateUser(claims model.IDTokenClaims) (*model.User, error) {
+	log := slog.With(
+		slog.String("func", "linkOrCreateUser"),
+		slog.String("service", "googleAuthentication"))
+
+	user, err := g.userRepository.GetByGoogleId(claims.Subject)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrGetUser
+	}
+
+	if user != nil {
+		return user, nil
+	}
+
+	if !claims.EmailVerified || claims.Email == "" {
+		log.Warn("Google account without verified email: " + claims.Subject)
+		return nil, model.ErrOIDCEmailNotVerified
+	}
+
+	user, err = g.userRepository.GetByEmail(claims.Email)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrGetUser
+	}
+
+	if user != nil && !user.IsEmailConfirmed {
+		log.Warn("Refusing to link google account to unconfirmed email: " + user.Id)
+		return nil, model.ErrOIDCAccountNotLinked
+	}
+
+	if user != nil {
+		if err := g.userRepository.UpdateGoogleId(user.Id, claims.Subject); err != nil {
+			log.Error("Error: " + err.Error())
+			return nil, model.ErrCreateUser
+		}
+
+		log.Info("Google account linked to existing user: " + user.Id)
+		user.GoogleId = &claims.Subject
+		return user, nil
+	}
+
+	affiliation, err := g.emailDomainPolicy.Affiliation(claims.Email)
+	if err != nil {
+		log.Warn("Email domain not allowed: " + claims.Email)
+		return nil, err
+	}
+
+	password, err := util.GenerateRandomToken(32)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrCreateUser
+	}
+
+	hashedPassword, err := Hash(password)
+	if err != nil {
+		log.Error("Error trying to hashed password")
+		return nil, model.ErrHashPassword
+	}
+
+	userPayLoad := model.UserPayLoad{
+		Name:  googleUserName(claims),
+		Email: claims.Email,
+	}
+
+	user, err = userPayLoad.ToUser(string(hashedPassword))
+	if err != nil {
+		log.Error("Error trying to convert userPayload to User")
+		return nil, model.ErrConvertUserPayLoadToUser
+	}
+
+	user.GoogleId = &claims.Subject
+	user.Affiliation = affiliation
+	user.IsEmailConfirmed = true
+
+	if err := g.userRepository.Create(*user); err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrCreateUser
+	}
+
+	log.Info("User created from google account: " + user.Id)
+	return user, nil
+}
+
+func googleUserName(claims model.IDTokenClaims) string {
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name = strings.Split(claims.Email, "@")[0]
+	}
+
+	runes := []rune(name)
+	if len(runes) > userNameMaxLength {
+		name = string(runes[:userNameMaxLength])
+	}
+
+	return name
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OVillas/user-api/config"
+	"github.com/OVillas/user-api/mocks"
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTokens(t *testing.T) {
+	t.Helper()
+
+	config.JWTIssuer = "conectauerj-user-api"
+	config.JWTAudience = "conectauerj"
+	config.AccessTokenTTL = 15 * time.Minute
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.pem"), data, 0o600))
+	require.NoError(t, util.LoadSigningKeys(dir, ""))
+}
+
+// authorizeWithStubIdP starts a google login and points the stub IdP at the
+// nonce and PKCE challenge it received, as the real IdP would.
+func authorizeWithStubIdP(t *testing.T, idp *stubIdP, g model.GoogleAuthenticationService) (*model.OIDCAuthorization, string) {
+	t.Helper()
+
+	authorization, err := g.Authorize()
+	require.NoError(t, err)
+
+	authorizationURL, err := url.Parse(authorization.AuthorizationURL)
+	require.NoError(t, err)
+
+	query := authorizationURL.Query()
+	idp.codeChallenge = query.Get("code_challenge")
+	idp.idTokenClaims = func() jwt.MapClaims { return idp.claims(query.Get("nonce")) }
+
+	return authorization, query.Get("state")
+}
+
+func TestGoogleCallbackRejectsStateFromAnotherBrowser(t *testing.T) {
+	setupTokens(t)
+	idp := newStubIdP(t)
+
+	g := NewGoogleAuthenticationService(mocks.NewUserRepository(t), &authenticationService{}, idp.provider(), nil)
+
+	_, victimState := authorizeWithStubIdP(t, idp, g)
+	attackerAuthorization, _ := authorizeWithStubIdP(t, idp, g)
+
+	callback := model.OIDCCallback{Code: stubCode, State: victimState}
+
+	_, err := g.Callback(callback, attackerAuthorization.StateToken, model.ClientInfo{})
+	assert.ErrorIs(t, err, model.ErrOIDCInvalidState)
+
+	_, err = g.Callback(callback, "", model.ClientInfo{})
+	assert.ErrorIs(t, err, model.ErrOIDCInvalidState)
+}
+
+func TestGoogleCallbackRequiresSecondFactor(t *testing.T) {
+	setupTokens(t)
+	idp := newStubIdP(t)
+
+	user := &model.User{Id: testUserId, Email: "ana@uerj.br", IsEmailConfirmed: true}
+	googleId := "google-subject"
+	user.GoogleId = &googleId
+
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetByGoogleId", "google-subject").Return(user, nil).Once()
+
+	totpRepository := mocks.NewTOTPRepository(t)
+	totpRepository.On("GetByUserId", testUserId).Return(&model.UserTOTP{UserId: testUserId, Enabled: true}, nil).Once()
+
+	g := NewGoogleAuthenticationService(userRepository, &authenticationService{totpRepository: totpRepository}, idp.provider(), nil)
+
+	authorization, state := authorizeWithStubIdP(t, idp, g)
+
+	tokenPair, err := g.Callback(model.OIDCCallback{Code: stubCode, State: state}, authorization.StateToken, model.ClientInfo{})
+	require.NoError(t, err)
+	assert.True(t, tokenPair.MFARequired)
+	assert.Empty(t, tokenPair.AccessToken)
+
+	principal, err := util.ParseMFAToken(tokenPair.MFAToken)
+	require.NoError(t, err)
+	assert.Equal(t, testUserId, principal.Id)
+}
+
+func TestGoogleCallbackDoesNotLinkUnconfirmedAccount(t *testing.T) {
+	setupTokens(t)
+	idp := newStubIdP(t)
+
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetByGoogleId", "google-subject").Return(nil, nil).Once()
+	userRepository.On("GetByEmail", "ana@uerj.br").Return(&model.User{Id: testUserId, Email: "ana@uerj.br"}, nil).Once()
+
+	g := NewGoogleAuthenticationService(userRepository, &authenticationService{}, idp.provider(), nil)
+
+	authorization, state := authorizeWithStubIdP(t, idp, g)
+
+	_, err := g.Callback(model.OIDCCallback{Code: stubCode, State: state}, authorization.StateToken, model.ClientInfo{})
+	assert.ErrorIs(t, err, model.ErrOIDCAccountNotLinked)
+	userRepository.AssertNotCalled(t, "UpdateGoogleId", mock.Anything, mock.Anything)
+}
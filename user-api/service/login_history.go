@@ -0,0 +1,150 @@
+package service
+
+import (
+	"fmt"
+	"html"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/google/uuid"
+)
+
+type loginHistoryService struct {
+	loginHistoryRepository model.LoginHistoryRepository
+	emailService           model.EmailService
+}
+
+func NewLoginHistoryService(loginHistoryRepository model.LoginHistoryRepository, emailService model.EmailService) model.LoginHistoryService {
+	return &loginHistoryService{
+		loginHistoryRepository: loginHistoryRepository,
+		emailService:           emailService,
+	}
+}
+
+func (l *loginHistoryService) Record(user model.User, client model.ClientInfo, method string, success bool) error {
+	log := slog.With(
+		slog.String("func", "Record"),
+		slog.String("service", "loginHistory"))
+
+	deviceFingerprint := deviceFingerprint(client)
+
+	newDevice := false
+	if success {
+		var err error
+		newDevice, err = l.isNewDevice(user.Id, deviceFingerprint)
+		if err != nil {
+			log.Error("Error: " + err.Error())
+			return model.ErrRecordLogin
+		}
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrRecordLogin
+	}
+
+	err = l.loginHistoryRepository.Create(model.LoginEvent{
+		Id:                id.String(),
+		UserId:            user.Id,
+		Method:            method,
+		Success:           success,
+		IP:                client.IP,
+		UserAgent:         truncate(client.UserAgent, sessionUserAgentMaxLength),
+		DeviceFingerprint: deviceFingerprint,
+	})
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrRecordLogin
+	}
+
+	if newDevice {
+		l.sendNewDeviceEmail(user.Email, client, method)
+	}
+
+	log.Info("Login recorded successfully")
+	return nil
+}
+
+func (l *loginHistoryService) GetByUserId(userId string) ([]model.LoginEventResponse, error) {
+	log := slog.With(
+		slog.String("func", "GetByUserId"),
+		slog.String("service", "loginHistory"))
+
+	loginEvents, err := l.loginHistoryRepository.GetByUserId(userId)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	loginEventsResponse := make([]model.LoginEventResponse, 0, len(loginEvents))
+	for _, loginEvent := range loginEvents {
+		loginEventsResponse = append(loginEventsResponse, model.LoginEventResponse{
+			Method:    loginEvent.Method,
+			Success:   loginEvent.Success,
+			IP:        loginEvent.IP,
+			UserAgent: loginEvent.UserAgent,
+			CreatedAt: loginEvent.CreatedAt,
+		})
+	}
+
+	log.Info("Login history found successfully")
+	return loginEventsResponse, nil
+}
+
+// deviceFingerprint identifies the device a login came from. Two logins count
+// as the same device when they share the exact User-Agent and come from the
+// same network, the /24 for IPv4 or the /48 for IPv6, so a browser moving
+// between addresses handed out by one provider is not reported again while
+// the same browser version on another network is.
+func deviceFingerprint(client model.ClientInfo) string {
+	network := ""
+	if ip := net.ParseIP(client.IP); ip != nil {
+		if ipv4 := ip.To4(); ipv4 != nil {
+			network = ipv4.Mask(net.CIDRMask(24, 32)).String()
+		} else {
+			network = ip.Mask(net.CIDRMask(48, 128)).String()
+		}
+	}
+
+	return util.HashToken(client.UserAgent + "|" + network)
+}
+
+func (l *loginHistoryService) isNewDevice(userId string, deviceFingerprint string) (bool, error) {
+	hasSuccessfulLogin, err := l.loginHistoryRepository.HasSuccessfulLogin(userId)
+	if err != nil || !hasSuccessfulLogin {
+		return false, err
+	}
+
+	knownDevice, err := l.loginHistoryRepository.HasSuccessfulLoginFromDevice(userId, deviceFingerprint)
+	if err != nil {
+		return false, err
+	}
+
+	return !knownDevice, nil
+}
+
+func (l *loginHistoryService) sendNewDeviceEmail(email string, client model.ClientInfo, method string) {
+	log := slog.With(
+		slog.String("func", "sendNewDeviceEmail"),
+		slog.String("service", "loginHistory"))
+
+	subject := "Novo acesso à sua conta"
+	content := fmt.Sprintf("<h1>Olá!</h1><p>Detectamos um acesso à sua conta a partir de um novo dispositivo em <b>%s</b>.</p><p>Dispositivo: %s<br>IP: %s<br>Método: %s</p><p>Se não foi você, recomendamos redefinir sua senha e encerrar as sessões ativas.</p>",
+		time.Now().Format("02/01/2006 15:04"),
+		html.EscapeString(client.UserAgent),
+		html.EscapeString(client.IP),
+		method,
+	)
+	to := []string{email}
+
+	if err := l.emailService.SendEmail(subject, content, to); err != nil {
+		log.Error("Errors: " + err.Error())
+		return
+	}
+
+	log.Info("New device email send successfully")
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/OVillas/user-api/mocks"
+	"github.com/OVillas/user-api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testUserAgent = "Mozilla/5.0 (X11; Linux x86_64) Firefox/124.0"
+
+func TestDeviceFingerprint(t *testing.T) {
+	testCases := []struct {
+		name     string
+		first    model.ClientInfo
+		second   model.ClientInfo
+		sameHash bool
+	}{
+		{
+			name:     "same ipv4 network",
+			first:    model.ClientInfo{IP: "200.20.10.4", UserAgent: testUserAgent},
+			second:   model.ClientInfo{IP: "200.20.10.201", UserAgent: testUserAgent},
+			sameHash: true,
+		},
+		{
+			name:     "other ipv4 network",
+			first:    model.ClientInfo{IP: "200.20.10.4", UserAgent: testUserAgent},
+			second:   model.ClientInfo{IP: "200.20.11.4", UserAgent: testUserAgent},
+			sameHash: false,
+		},
+		{
+			name:     "same ipv6 network",
+			first:    model.ClientInfo{IP: "2804:14c:1::1", UserAgent: testUserAgent},
+			second:   model.ClientInfo{IP: "2804:14c:1:ff::9", UserAgent: testUserAgent},
+			sameHash: true,
+		},
+		{
+			name:     "other ipv6 network",
+			first:    model.ClientInfo{IP: "2804:14c:1::1", UserAgent: testUserAgent},
+			second:   model.ClientInfo{IP: "2804:14c:2::1", UserAgent: testUserAgent},
+			sameHash: false,
+		},
+		{
+			name:     "other user agent",
+			first:    model.ClientInfo{IP: "200.20.10.4", UserAgent: testUserAgent},
+			second:   model.ClientInfo{IP: "200.20.10.4", UserAgent: "Mozilla/5.0 (iPhone) Safari/604.1"},
+			sameHash: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.sameHash, deviceFingerprint(testCase.first) == deviceFingerprint(testCase.second))
+		})
+	}
+}
+
+func TestIsNewDevice(t *testing.T) {
+	repositoryErr := errors.New("connection refused")
+
+	testCases := []struct {
+		name               string
+		hasSuccessfulLogin bool
+		knownDevice        bool
+		err                error
+		expected           bool
+	}{
+		{name: "first login", hasSuccessfulLogin: false, expected: false},
+		{name: "known device", hasSuccessfulLogin: true, knownDevice: true, expected: false},
+		{name: "new device", hasSuccessfulLogin: true, knownDevice: false, expected: true},
+		{name: "repository error", hasSuccessfulLogin: true, err: repositoryErr, expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			loginHistoryRepository := mocks.NewLoginHistoryRepository(t)
+			loginHistoryRepository.On("HasSuccessfulLogin", testUserId).Return(testCase.hasSuccessfulLogin, nil).Once()
+			if testCase.hasSuccessfulLogin {
+				loginHistoryRepository.On("HasSuccessfulLoginFromDevice", testUserId, "fingerprint").Return(testCase.knownDevice, testCase.err).Once()
+			}
+
+			l := &loginHistoryService{loginHistoryRepository: loginHistoryRepository}
+
+			newDevice, err := l.isNewDevice(testUserId, "fingerprint")
+
+			assert.ErrorIs(t, err, testCase.err)
+			assert.Equal(t, testCase.expected, newDevice)
+		})
+	}
+}
+
+func TestRecordSkipsNewDeviceAlertOnFirstLogin(t *testing.T) {
+	user := model.User{Id: testUserId, Email: "ana@uerj.br"}
+	client := model.ClientInfo{IP: "200.20.10.4", UserAgent: testUserAgent}
+
+	loginHistoryRepository := mocks.NewLoginHistoryRepository(t)
+	loginHistoryRepository.On("HasSuccessfulLogin", testUserId).Return(false, nil).Once()
+	loginHistoryRepository.On("Create", mock.MatchedBy(func(loginEvent model.LoginEvent) bool {
+		return loginEvent.Success && loginEvent.DeviceFingerprint == deviceFingerprint(client)
+	})).Return(nil).Once()
+
+	l := &loginHistoryService{
+		loginHistoryRepository: loginHistoryRepository,
+		emailService:           mocks.NewEmailService(t),
+	}
+
+	require.NoError(t, l.Record(user, client, model.LoginMethodPassword, true))
+}
+
+func TestRecordAlertsOnNewDevice(t *testing.T) {
+	user := model.User{Id: testUserId, Email: "ana@uerj.br"}
+	client := model.ClientInfo{IP: "200.20.10.4", UserAgent: testUserAgent}
+
+	loginHistoryRepository := mocks.NewLoginHistoryRepository(t)
+	loginHistoryRepository.On("HasSuccessfulLogin", testUserId).Return(true, nil).Once()
+	loginHistoryRepository.On("HasSuccessfulLoginFromDevice", testUserId, deviceFingerprint(client)).Return(false, nil).Once()
+	loginHistoryRepository.On("Create", mock.Anything).Return(nil).Once()
+
+	emailService := mocks.NewEmailService(t)
+	emailService.On("SendEmail", mock.Anything, mock.Anything, []string{"ana@uerj.br"}).Return(nil).Once()
+
+	l := &loginHistoryService{
+		loginHistoryRepository: loginHistoryRepository,
+		emailService:           emailService,
+	}
+
+	require.NoError(t, l.Record(user, client, model.LoginMethodPassword, true))
+}
+
+func TestRecordSkipsDeviceCheckOnFailedLogin(t *testing.T) {
+	loginHistoryRepository := mocks.NewLoginHistoryRepository(t)
+	loginHistoryRepository.On("Create", mock.MatchedBy(func(loginEvent model.LoginEvent) bool {
+		return !loginEvent.Success
+	})).Return(nil).Once()
+
+	l := &loginHistoryService{
+		loginHistoryRepository: loginHistoryRepository,
+		emailService:           mocks.NewEmailService(t),
+	}
+
+	require.NoError(t, l.Record(model.User{Id: testUserId}, model.ClientInfo{IP: "200.20.10.4"}, model.LoginMethodPassword, false))
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+)
+
+// The IP key is c.RealIP(), which only honours X-Forwarded-For from the
+// configured TRUSTED_PROXIES, so a client cannot rotate it by hand. A whole
+// campus can share one NAT address, so the IP lockout only catches sprays
+// across many accounts; the per-account lockout is what protects a user.
+const (
+	loginDelayAfterFailures   = 3
+	loginBaseDelay            = time.Second
+	loginMaxDelay             = 30 * time.Second
+	loginLockoutDuration      = 15 * time.Minute
+	loginEmailLockoutFailures = 10
+	loginIPLockoutFailures    = 1000
+)
+
+func loginEmailKey(email string) string {
+	return "email:" + normalizeEmail(email)
+}
+
+func loginIPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// CheckLoginThrottle lets other login steps, like the second factor, share
+// the lockout of the password login.
+func (a *authenticationService) CheckLoginThrottle(email string, ip string) error {
+	return a.checkLoginThrottle(loginEmailKey(email), loginIPKey(ip))
+}
+
+func (a *authenticationService) ResetLoginFailures(email string) error {
+	return a.loginAttemptStore.Reset(loginEmailKey(email))
+}
+
+func (a *authenticationService) checkLoginThrottle(keys ...string) error {
+	now := time.Now()
+	var retryAfter time.Duration
+
+	for _, key := range keys {
+		attempt, err := a.loginAttemptStore.Get(key)
+		if err != nil {
+			return err
+		}
+
+		if attempt == nil {
+			continue
+		}
+
+		if attempt.LockedUntil != nil && now.After(*attempt.LockedUntil) {
+			if err := a.loginAttemptStore.Reset(key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var wait time.Duration
+		if attempt.LockedUntil != nil {
+			wait = attempt.LockedUntil.Sub(now)
+		}
+
+		if delay := loginDelay(attempt.Failures); attempt.LastFailureAt.Add(delay).Sub(now) > wait {
+			wait = attempt.LastFailureAt.Add(delay).Sub(now)
+		}
+
+		if wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return &model.LoginThrottledError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+func (a *authenticationService) RegisterLoginFailure(email string, ip string, user *model.User) {
+	log := slog.With(
+		slog.String("func", "RegisterLoginFailure"),
+		slog.String("service", "authentication"))
+
+	attempt, err := a.loginAttemptStore.RegisterFailure(loginEmailKey(email))
+	if err != nil {
+		log.Error("Error: " + err.Error())
+	}
+
+	if attempt != nil && attempt.Failures == loginEmailLockoutFailures {
+		lockedUntil := time.Now().Add(loginLockoutDuration)
+		if err := a.loginAttemptStore.Lock(loginEmailKey(email), lockedUntil); err != nil {
+			log.Error("Error: " + err.Error())
+		}
+
+		log.Warn("Account locked after repeated login failures: " + email)
+		if user != nil {
+			a.sendLockoutEmail(user.Email, lockedUntil)
+		}
+	}
+
+	if ip == "" {
+		return
+	}
+
+	attempt, err = a.loginAttemptStore.RegisterFailure(loginIPKey(ip))
+	if err != nil {
+		log.Error("Error: " + err.Error())
+	}
+
+	if attempt != nil && attempt.Failures == loginIPLockoutFailures {
+		if err := a.loginAttemptStore.Lock(loginIPKey(ip), time.Now().Add(loginLockoutDuration)); err != nil {
+			log.Error("Error: " + err.Error())
+		}
+
+		log.Warn("IP locked after repeated login failures: " + ip)
+	}
+}
+
+func (a *authenticationService) sendLockoutEmail(email string, lockedUntil time.Time) {
+	log := slog.With(
+		slog.String("func", "sendLockoutEmail"),
+		slog.String("service", "authentication"))
+
+	subject := "Conta bloqueada temporariamente"
+	content := fmt.Sprintf("<h1>Olá!</h1><p>Detectamos muitas tentativas de login sem sucesso na sua conta. Por segurança, o acesso foi bloqueado até <b>%s</b>.</p><p>Se não foi você, recomendamos redefinir sua senha.</p>", lockedUntil.Format("02/01/2006 15:04"))
+	to := []string{email}
+
+	if err := a.emailService.SendEmail(subject, content, to); err != nil {
+		log.Error("Errors: " + err.Error())
+		return
+	}
+
+	log.Info("Lockout email send successfully")
+}
+
+func loginDelay(failures int) time.Duration {
+	if failures < loginDelayAfterFailures {
+		return 0
+	}
+
+	delay := loginBaseDelay * time.Duration(math.Pow(2, float64(failures-loginDelayAfterFailures)))
+	if delay > loginMaxDelay || delay <= 0 {
+		return loginMaxDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginEmailKeyIsCaseAndWhitespaceInsensitive(t *testing.T) {
+	assert.Equal(t, loginEmailKey("ana@uerj.br"), loginEmailKey(" Ana@UERJ.br\t"))
+}
+
+func TestCheckLoginThrottleCountsFailuresAcrossEmailVariants(t *testing.T) {
+	a := &authenticationService{loginAttemptStore: repository.NewInMemoryLoginAttemptRepository(time.Hour)}
+
+	for _, email := range []string{"ana@uerj.br", "ANA@uerj.br", " ana@UERJ.BR "} {
+		a.RegisterLoginFailure(email, "", nil)
+	}
+
+	err := a.checkLoginThrottle(loginEmailKey("Ana@Uerj.Br"))
+
+	var throttledErr *model.LoginThrottledError
+	require.ErrorAs(t, err, &throttledErr)
+	assert.Greater(t, throttledErr.RetryAfter, time.Duration(0))
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/golang-jwt/jwt"
+)
+
+const oidcJWKSRefreshInterval = time.Minute
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type oidcTokenResponse struct {
+	IDToken string `json:"id_token"`
+	Error   string `json:"error"`
+}
+
+type oidcProvider struct {
+	issuer        string
+	clientID      string
+	clientSecret  string
+	redirectURL   string
+	jwksURL       string
+	httpClient    *http.Client
+	mutex         sync.Mutex
+	discovery     *oidcDiscovery
+	keys          map[string]*rsa.PublicKey
+	keysFetchedAt time.Time
+}
+
+func NewOIDCProvider(issuer string, clientID string, clientSecret string, redirectURL string, jwksURL string) model.OIDCProvider {
+	return &oidcProvider{
+		issuer:       strings.TrimSuffix(issuer, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		jwksURL:      jwksURL,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		keys:         make(map[string]*rsa.PublicKey),
+	}
+}
+
+func (op *oidcProvider) AuthorizationURL(state string, nonce string, codeChallenge string) (string, error) {
+	discovery, err := op.discover()
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("client_id", op.clientID)
+	query.Set("redirect_uri", op.redirectURL)
+	query.Set("response_type", "code")
+	query.Set("scope", "openid email profile")
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+
+	return discovery.AuthorizationEndpoint + "?" + query.Encode(), nil
+}
+
+func (op *oidcProvider) Exchange(code string, codeVerifier string) (string, error) {
+	log := slog.With(
+		slog.String("func", "Exchange"),
+		slog.String("service", "oidc"))
+
+	discovery, err := op.discover()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("redirect_uri", op.redirectURL)
+	form.Set("client_id", op.clientID)
+	form.Set("client_secret", op.clientSecret)
+
+	response, err := op.httpClient.PostForm(discovery.TokenEndpoint, form)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return "", model.ErrOIDCExchange
+	}
+	defer response.Body.Close()
+
+	var tokenResponse oidcTokenResponse
+	if err := json.NewDecoder(response.Body).Decode(&tokenResponse); err != nil {
+		log.Error("Error: " + err.Error())
+		return "", model.ErrOIDCExchange
+	}
+
+	if response.StatusCode != http.StatusOK || tokenResponse.IDToken == "" {
+		log.Warn("Token endpoint refused the code: " + tokenResponse.Error)
+		return "", model.ErrOIDCExchange
+	}
+
+	return tokenResponse.IDToken, nil
+}
+
+func (op *oidcProvider) VerifyIDToken(rawIDToken string, nonce string) (*model.IDTokenClaims, error) {
+	log := slog.With(
+		slog.String("func", "VerifyIDToken"),
+		slog.String("service", "oidc"))
+
+	token, err := jwt.Parse(rawIDToken, op.getVerificationKey)
+	if err != nil {
+		log.Warn("Error: " + err.Error())
+		return nil, model.ErrOIDCInvalidIDToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, model.ErrOIDCInvalidIDToken
+	}
+
+	issuer, _ := claims["iss"].(string)
+	if issuer != op.issuer && "https://"+issuer != op.issuer {
+		log.Warn("Unexpected issuer: " + issuer)
+		return nil, model.ErrOIDCInvalidIDToken
+	}
+
+	if !op.hasAudience(claims["aud"]) {
+		log.Warn("Id token not issued for this client")
+		return nil, model.ErrOIDCInvalidIDToken
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce == "" || tokenNonce != nonce {
+		log.Warn("Id token nonce mismatch")
+		return nil, model.ErrOIDCInvalidIDToken
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, model.ErrOIDCInvalidIDToken
+	}
+
+	email, _ := claims["email"].(string)
+	name, _ := claims["name"].(string)
+
+	emailVerified := false
+	switch value := claims["email_verified"].(type) {
+	case bool:
+		emailVerified = value
+	case string:
+		emailVerified = value == "true"
+	}
+
+	return &model.IDTokenClaims{
+		Subject:       subject,
+		Email:         email,
+		EmailVerified: emailVerified,
+		Name:          name,
+	}, nil
+}
+
+func (op *oidcProvider) hasAudience(audience interface{}) bool {
+	switch value := audience.(type) {
+	case string:
+		return value == op.clientID
+	case []interface{}:
+		for _, aud := range value {
+			if aud == op.clientID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (op *oidcProvider) getVerificationKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+		return nil, model.ErrUnexpectedSigningMethod
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+
+	if key, ok := op.keys[kid]; ok {
+		return key, nil
+	}
+
+	if time.Since(op.keysFetchedAt) < oidcJWKSRefreshInterval {
+		return nil, model.ErrOIDCInvalidIDToken
+	}
+
+	if err := op.fetchKeys(); err != nil {
+		return nil, err
+	}
+
+	if key, ok := op.keys[kid]; ok {
+		return key, nil
+	}
+
+	return nil, model.ErrOIDCInvalidIDToken
+}
+
+func (op *oidcProvider) discover() (*oidcDiscovery, error) {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+
+	return op.discoverLocked()
+}
+
+func (op *oidcProvider) discoverLocked() (*oidcDiscovery, error) {
+	log := slog.With(
+		slog.String("func", "discover"),
+		slog.String("service", "oidc"))
+
+	if op.discovery != nil {
+		return op.discovery, nil
+	}
+
+	response, err := op.httpClient.Get(op.issuer + "/.well-known/openid-configuration")
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrOIDCDiscovery
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		log.Error("Unexpected discovery status: " + response.Status)
+		return nil, model.ErrOIDCDiscovery
+	}
+
+	var discovery oidcDiscovery
+	if err := json.NewDecoder(response.Body).Decode(&discovery); err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrOIDCDiscovery
+	}
+
+	if op.jwksURL != "" {
+		discovery.JWKSURI = op.jwksURL
+	}
+
+	op.discovery = &discovery
+	return op.discovery, nil
+}
+
+func (op *oidcProvider) fetchKeys() error {
+	log := slog.With(
+		slog.String("func", "fetchKeys"),
+		slog.String("service", "oidc"))
+
+	discovery, err := op.discoverLocked()
+	if err != nil {
+		return err
+	}
+
+	op.keysFetchedAt = time.Now()
+
+	response, err := op.httpClient.Get(discovery.JWKSURI)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrOIDCDiscovery
+	}
+	defer response.Body.Close()
+
+	var keySet jsonWebKeySet
+	if err := json.NewDecoder(response.Body).Decode(&keySet); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrOIDCDiscovery
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, key := range keySet.Keys {
+		if key.Kty != "RSA" {
+			continue
+		}
+
+		publicKey, err := rsaPublicKeyFromJWK(key)
+		if err != nil {
+			log.Warn("Ignoring invalid json web key: " + key.Kid)
+			continue
+		}
+
+		keys[key.Kid] = publicKey
+	}
+
+	op.keys = keys
+	return nil
+}
+
+func rsaPublicKeyFromJWK(key jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
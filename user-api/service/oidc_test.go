@@ -0,0 +1,159 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	stubClientID = "conectauerj-client"
+	stubKeyId    = "stub-key"
+	stubCode     = "authorization-code"
+)
+
+// stubIdP serves the discovery document, JWKS and token endpoint of a fake
+// OpenID provider. The token endpoint enforces PKCE and answers with the id
+// token built by idTokenClaims.
+type stubIdP struct {
+	server        *httptest.Server
+	key           *rsa.PrivateKey
+	codeChallenge string
+	idTokenClaims func() jwt.MapClaims
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	idp := &stubIdP{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                idp.server.URL,
+			AuthorizationEndpoint: idp.server.URL + "/authorize",
+			TokenEndpoint:         idp.server.URL + "/token",
+			JWKSURI:               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jsonWebKeySet{Keys: []jsonWebKey{{
+			Kty: "RSA",
+			Kid: stubKeyId,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		challenge := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if r.PostFormValue("code") != stubCode ||
+			base64.RawURLEncoding.EncodeToString(challenge[:]) != idp.codeChallenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(oidcTokenResponse{Error: "invalid_grant"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(oidcTokenResponse{IDToken: idp.sign(t, stubKeyId, idp.idTokenClaims())})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+func (idp *stubIdP) provider() model.OIDCProvider {
+	return NewOIDCProvider(idp.server.URL, stubClientID, "secret", "http://localhost/callback", "")
+}
+
+func (idp *stubIdP) claims(nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"aud":            stubClientID,
+		"sub":            "google-subject",
+		"email":          "ana@uerj.br",
+		"email_verified": true,
+		"name":           "Ana",
+		"nonce":          nonce,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func (idp *stubIdP) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(idp.key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func TestOIDCProviderVerifyIDToken(t *testing.T) {
+	idp := newStubIdP(t)
+
+	testCases := []struct {
+		name   string
+		kid    string
+		mutate func(claims jwt.MapClaims)
+		valid  bool
+	}{
+		{name: "valid token", kid: stubKeyId, mutate: func(jwt.MapClaims) {}, valid: true},
+		{name: "bad nonce", kid: stubKeyId, mutate: func(claims jwt.MapClaims) { claims["nonce"] = "other-nonce" }},
+		{name: "bad audience", kid: stubKeyId, mutate: func(claims jwt.MapClaims) { claims["aud"] = "other-client" }},
+		{name: "bad issuer", kid: stubKeyId, mutate: func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" }},
+		{name: "expired token", kid: stubKeyId, mutate: func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Minute).Unix() }},
+		{name: "unknown kid", kid: "unknown-key", mutate: func(jwt.MapClaims) {}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			claims := idp.claims("expected-nonce")
+			testCase.mutate(claims)
+
+			idTokenClaims, err := idp.provider().VerifyIDToken(idp.sign(t, testCase.kid, claims), "expected-nonce")
+
+			if testCase.valid {
+				require.NoError(t, err)
+				assert.Equal(t, "google-subject", idTokenClaims.Subject)
+				assert.True(t, idTokenClaims.EmailVerified)
+				return
+			}
+
+			assert.ErrorIs(t, err, model.ErrOIDCInvalidIDToken)
+		})
+	}
+}
+
+func TestOIDCProviderExchangeRejectsPKCEMismatch(t *testing.T) {
+	idp := newStubIdP(t)
+	challenge := sha256.Sum256([]byte("right-verifier"))
+	idp.codeChallenge = base64.RawURLEncoding.EncodeToString(challenge[:])
+	idp.idTokenClaims = func() jwt.MapClaims { return idp.claims("nonce") }
+
+	provider := idp.provider()
+
+	_, err := provider.Exchange(stubCode, "wrong-verifier")
+	assert.ErrorIs(t, err, model.ErrOIDCExchange)
+
+	idToken, err := provider.Exchange(stubCode, "right-verifier")
+	require.NoError(t, err)
+	assert.NotEmpty(t, idToken)
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/google/uuid"
+)
+
+const personalAccessTokenTouchInterval = time.Minute
+
+type personalAccessTokenService struct {
+	userRepository                model.UserRepository
+	personalAccessTokenRepository model.PersonalAccessTokenRepository
+}
+
+func NewPersonalAccessTokenService(userRepository model.UserRepository, personalAccessTokenRepository model.PersonalAccessTokenRepository) model.PersonalAccessTokenService {
+	return &personalAccessTokenService{
+		userRepository:                userRepository,
+		personalAccessTokenRepository: personalAccessTokenRepository,
+	}
+}
+
+func (p *personalAccessTokenService) Create(userId string, personalAccessTokenPayLoad model.PersonalAccessTokenPayLoad) (*model.PersonalAccessTokenCreated, error) {
+	log := slog.With(
+		slog.String("func", "Create"),
+		slog.String("service", "personalAccessToken"))
+
+	user, err := p.userRepository.GetById(userId)
+	if err != nil {
+		log.Error("failed to get user by id")
+		return nil, model.ErrGetUser
+	}
+
+	if user == nil {
+		log.Warn("User not found with this id")
+		return nil, model.ErrUserNotFound
+	}
+
+	scopes := uniqueScopes(personalAccessTokenPayLoad.Scopes)
+	for _, scope := range scopes {
+		if scope == model.ScopeAdmin && user.Role != model.RoleAdmin {
+			log.Warn("User without admin role requested admin scope: " + user.Id)
+			return nil, model.ErrScopeNotAllowed
+		}
+	}
+
+	randomToken, err := util.GenerateRandomToken(32)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrGenPersonalAccessToken
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrGenPersonalAccessToken
+	}
+
+	token := util.PersonalTokenPrefix + randomToken
+	personalAccessToken := model.PersonalAccessToken{
+		Id:        id.String(),
+		UserId:    user.Id,
+		Name:      personalAccessTokenPayLoad.Name,
+		TokenHash: util.HashToken(token),
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: time.Now().AddDate(0, 0, personalAccessTokenPayLoad.ExpiresInDays),
+		CreatedAt: time.Now(),
+	}
+
+	if err := p.personalAccessTokenRepository.Create(personalAccessToken); err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrGenPersonalAccessToken
+	}
+
+	log.Info("Personal access token created successfully")
+	return &model.PersonalAccessTokenCreated{
+		PersonalAccessTokenResponse: toPersonalAccessTokenResponse(personalAccessToken),
+		Token:                       token,
+	}, nil
+}
+
+func (p *personalAccessTokenService) GetAll(userId string) ([]model.PersonalAccessTokenResponse, error) {
+	log := slog.With(
+		slog.String("func", "GetAll"),
+		slog.String("service", "personalAccessToken"))
+
+	personalAccessTokens, err := p.personalAccessTokenRepository.GetActiveByUserId(userId)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	personalAccessTokensResponse := make([]model.PersonalAccessTokenResponse, 0, len(personalAccessTokens))
+	for _, personalAccessToken := range personalAccessTokens {
+		personalAccessTokensResponse = append(personalAccessTokensResponse, toPersonalAccessTokenResponse(personalAccessToken))
+	}
+
+	log.Info("Personal access tokens found successfully")
+	return personalAccessTokensResponse, nil
+}
+
+func (p *personalAccessTokenService) Revoke(userId string, id string) error {
+	log := slog.With(
+		slog.String("func", "Revoke"),
+		slog.String("service", "personalAccessToken"))
+
+	personalAccessToken, err := p.personalAccessTokenRepository.GetById(id)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	if personalAccessToken == nil || personalAccessToken.UserId != userId || personalAccessToken.RevokedAt != nil {
+		log.Warn("Personal access token not found for this user: " + id)
+		return model.ErrPersonalAccessTokenNotFound
+	}
+
+	if err := p.personalAccessTokenRepository.Revoke(id); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrRevokePersonalAccessToken
+	}
+
+	log.Info("Personal access token revoked successfully")
+	return nil
+}
+
+func (p *personalAccessTokenService) Authenticate(token string) (*model.Principal, error) {
+	log := slog.With(
+		slog.String("func", "Authenticate"),
+		slog.String("service", "personalAccessToken"))
+
+	personalAccessToken, err := p.personalAccessTokenRepository.GetByTokenHash(util.HashToken(token))
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	if personalAccessToken == nil || personalAccessToken.RevokedAt != nil || time.Now().After(personalAccessToken.ExpiresAt) {
+		log.Warn("Personal access token invalid or expired")
+		return nil, model.ErrInvalidPersonalAccessToken
+	}
+
+	user, err := p.userRepository.GetById(personalAccessToken.UserId)
+	if err != nil {
+		log.Error("failed to get user by id")
+		return nil, model.ErrGetUser
+	}
+
+	if user == nil {
+		log.Warn("User not found with this id")
+		return nil, model.ErrInvalidPersonalAccessToken
+	}
+
+	if personalAccessToken.LastUsedAt == nil || time.Since(*personalAccessToken.LastUsedAt) > personalAccessTokenTouchInterval {
+		if err := p.personalAccessTokenRepository.UpdateLastUsedAt(personalAccessToken.Id, time.Now()); err != nil {
+			log.Warn("Error to update personal access token last used: " + err.Error())
+		}
+	}
+
+	role := user.Role
+	if role == "" {
+		role = model.RoleUser
+	}
+
+	return &model.Principal{
+		Id:        user.Id,
+		Name:      user.Name,
+		Email:     user.Email,
+		Roles:     []string{role},
+		Scopes:    strings.Fields(personalAccessToken.Scopes),
+		TokenId:   personalAccessToken.Id,
+		TokenType: util.TokenTypePersonal,
+		ExpiresAt: personalAccessToken.ExpiresAt,
+	}, nil
+}
+
+func toPersonalAccessTokenResponse(personalAccessToken model.PersonalAccessToken) model.PersonalAccessTokenResponse {
+	return model.PersonalAccessTokenResponse{
+		Id:         personalAccessToken.Id,
+		Name:       personalAccessToken.Name,
+		Scopes:     strings.Fields(personalAccessToken.Scopes),
+		ExpiresAt:  personalAccessToken.ExpiresAt,
+		LastUsedAt: personalAccessToken.LastUsedAt,
+		CreatedAt:  personalAccessToken.CreatedAt,
+	}
+}
+
+func uniqueScopes(scopes []string) []string {
+	seen := make(map[string]bool, len(scopes))
+	unique := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		unique = append(unique, scope)
+	}
+
+	return unique
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type profileService struct {
+	userRepository    model.UserRepository
+	profileRepository model.ProfileRepository
+}
+
+func NewProfileService(userRepository model.UserRepository, profileRepository model.ProfileRepository) model.ProfileService {
+	return &profileService{
+		userRepository:    userRepository,
+		profileRepository: profileRepository,
+	}
+}
+
+func (p *profileService) GetByUserId(userId string) (*model.ProfileResponse, error) {
+	log := slog.With(
+		slog.String("func", "GetByUserId"),
+		slog.String("service", "profile"))
+
+	user, err := p.userRepository.GetById(userId)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrGetUser
+	}
+
+	if user == nil {
+		log.Warn("User not found to get profile")
+		return nil, model.ErrUserNotFound
+	}
+
+	profile, err := p.profileRepository.GetByUserId(userId)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrGetProfile
+	}
+
+	if profile == nil {
+		profile = &model.Profile{UserId: userId}
+	}
+
+	log.Info("Profile found successfully")
+	return profile.ToProfileResponse(), nil
+}
+
+func (p *profileService) Update(userId string, profilePayLoad model.ProfilePayLoad) error {
+	log := slog.With(
+		slog.String("func", "Update"),
+		slog.String("service", "profile"))
+
+	user, err := p.userRepository.GetById(userId)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrGetUser
+	}
+
+	if user == nil {
+		log.Warn("User not found to update profile")
+		return model.ErrUserNotFound
+	}
+
+	profile := profilePayLoad.ToProfile(userId)
+	profile.Course = strings.TrimSpace(profile.Course)
+	profile.Campus = strings.TrimSpace(profile.Campus)
+	profile.Bio = strings.TrimSpace(profile.Bio)
+	profile.Pronouns = strings.TrimSpace(profile.Pronouns)
+	for i := range profile.Links {
+		profile.Links[i].Label = strings.TrimSpace(profile.Links[i].Label)
+	}
+
+	if err := p.profileRepository.Save(*profile); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrSaveProfile
+	}
+
+	log.Info("Profile updated successfully")
+	return nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"log/slog"
+
+	"github.com/OVillas/user-api/model"
+)
+
+type sessionService struct {
+	sessionRepository      model.SessionRepository
+	refreshTokenRepository model.RefreshTokenRepository
+}
+
+func NewSessionService(sessionRepository model.SessionRepository, refreshTokenRepository model.RefreshTokenRepository) model.SessionService {
+	return &sessionService{
+		sessionRepository:      sessionRepository,
+		refreshTokenRepository: refreshTokenRepository,
+	}
+}
+
+func (s *sessionService) GetAll(userId string, currentSessionId string) ([]model.SessionResponse, error) {
+	log := slog.With(
+		slog.String("func", "GetAll"),
+		slog.String("service", "session"))
+
+	sessions, err := s.sessionRepository.GetActiveByUserId(userId)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	sessionsResponse := make([]model.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		sessionsResponse = append(sessionsResponse, model.SessionResponse{
+			Id:         session.Id,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.Id == currentSessionId,
+		})
+	}
+
+	log.Info("Sessions found successfully")
+	return sessionsResponse, nil
+}
+
+func (s *sessionService) Revoke(userId string, sessionId string) error {
+	log := slog.With(
+		slog.String("func", "Revoke"),
+		slog.String("service", "session"))
+
+	session, err := s.sessionRepository.GetById(sessionId)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	if session == nil || session.UserId != userId || session.RevokedAt != nil {
+		log.Warn("Session not found for this user: " + sessionId)
+		return model.ErrSessionNotFound
+	}
+
+	if err := s.revoke(session.Id); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrRevokeSession
+	}
+
+	log.Info("Session revoked successfully")
+	return nil
+}
+
+func (s *sessionService) RevokeOthers(userId string, currentSessionId string) error {
+	log := slog.With(
+		slog.String("func", "RevokeOthers"),
+		slog.String("service", "session"))
+
+	sessions, err := s.sessionRepository.GetActiveByUserId(userId)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.Id == currentSessionId {
+			continue
+		}
+
+		if err := s.revoke(session.Id); err != nil {
+			log.Error("Error: " + err.Error())
+			return model.ErrRevokeSession
+		}
+	}
+
+	log.Info("Other sessions revoked successfully")
+	return nil
+}
+
+func (s *sessionService) revoke(sessionId string) error {
+	if err := s.sessionRepository.Revoke(sessionId); err != nil {
+		return err
+	}
+
+	return s.refreshTokenRepository.RevokeFamily(sessionId)
+}
+
+func truncate(value string, maxLength int) string {
+	runes := []rune(value)
+	if len(runes) > maxLength {
+		return string(runes[:maxLength])
+	}
+
+	return value
+}
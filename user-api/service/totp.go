@@ -0,0 +1,325 @@
+package service
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+)
+
+const (
+	totpIssuer          = "ConectaUERJ"
+	recoveryCodeCount   = 10
+	mfaTokenMaxAttempts = 5
+)
+
+type totpService struct {
+	userRepository        model.UserRepository
+	totpRepository        model.TOTPRepository
+	tokenRevocationStore  model.TokenRevocationStore
+	loginAttemptStore     model.LoginAttemptStore
+	authenticationService model.AuthenticationService
+	loginHistoryService   model.LoginHistoryService
+}
+
+func NewTOTPService(
+	userRepository model.UserRepository,
+	totpRepository model.TOTPRepository,
+	tokenRevocationStore model.TokenRevocationStore,
+	loginAttemptStore model.LoginAttemptStore,
+	authenticationService model.AuthenticationService,
+	loginHistoryService model.LoginHistoryService,
+) model.TOTPService {
+	return &totpService{
+		userRepository:        userRepository,
+		totpRepository:        totpRepository,
+		tokenRevocationStore:  tokenRevocationStore,
+		loginAttemptStore:     loginAttemptStore,
+		authenticationService: authenticationService,
+		loginHistoryService:   loginHistoryService,
+	}
+}
+
+func (t *totpService) Enroll(userId string) (*model.TOTPEnrollment, error) {
+	log := slog.With(
+		slog.String("func", "Enroll"),
+		slog.String("service", "totp"))
+
+	user, err := t.userRepository.GetById(userId)
+	if err != nil {
+		log.Error("failed to get user by id")
+		return nil, model.ErrGetUser
+	}
+
+	if user == nil {
+		log.Warn("User not found with this id")
+		return nil, model.ErrUserNotFound
+	}
+
+	userTOTP, err := t.totpRepository.GetByUserId(userId)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	if userTOTP != nil && userTOTP.Enabled {
+		log.Warn("Two-factor authentication already enabled for user: " + userId)
+		return nil, model.ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrGenTOTPSecret
+	}
+
+	if err := t.totpRepository.Save(model.UserTOTP{UserId: userId, Secret: secret}); err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrGenTOTPSecret
+	}
+
+	log.Info("TOTP enrollment started successfully")
+	return &model.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: util.TOTPProvisioningURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+func (t *totpService) Confirm(userId string, code string) (*model.RecoveryCodes, error) {
+	log := slog.With(
+		slog.String("func", "Confirm"),
+		slog.String("service", "totp"))
+
+	userTOTP, err := t.totpRepository.GetByUserId(userId)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	if userTOTP == nil {
+		log.Warn("TOTP not enrolled for user: " + userId)
+		return nil, model.ErrTOTPNotEnrolled
+	}
+
+	if userTOTP.Enabled {
+		log.Warn("Two-factor authentication already enabled for user: " + userId)
+		return nil, model.ErrTOTPAlreadyEnabled
+	}
+
+	if err := t.useTOTPCode(*userTOTP, code); err != nil {
+		log.Warn("Invalid TOTP code for user: " + userId)
+		return nil, err
+	}
+
+	recoveryCodes, err := t.generateRecoveryCodes(userId)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	if err := t.totpRepository.Enable(userId); err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("Two-factor authentication enabled successfully")
+	return recoveryCodes, nil
+}
+
+func (t *totpService) Disable(userId string, code string) error {
+	log := slog.With(
+		slog.String("func", "Disable"),
+		slog.String("service", "totp"))
+
+	if err := t.checkSecondFactor(userId, code); err != nil {
+		log.Warn("Invalid second factor to disable TOTP for user: " + userId)
+		return err
+	}
+
+	if err := t.totpRepository.Delete(userId); err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	log.Info("Two-factor authentication disabled successfully")
+	return nil
+}
+
+func (t *totpService) Verify(mfaVerify model.MFAVerify, client model.ClientInfo) (*model.TokenPair, error) {
+	log := slog.With(
+		slog.String("func", "Verify"),
+		slog.String("service", "totp"))
+
+	principal, err := util.ParseMFAToken(mfaVerify.MFAToken)
+	if err != nil {
+		log.Warn("Invalid mfa token")
+		return nil, model.ErrInvalidMFAToken
+	}
+
+	userId, jti, expiresAt := principal.Id, principal.TokenId, principal.ExpiresAt
+
+	revoked, err := t.tokenRevocationStore.IsRevoked(jti)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	if revoked {
+		log.Warn("Mfa token already used: " + jti)
+		return nil, model.ErrInvalidMFAToken
+	}
+
+	user, err := t.userRepository.GetById(userId)
+	if err != nil {
+		log.Error("failed to get user by id")
+		return nil, model.ErrGetUser
+	}
+
+	if user == nil {
+		log.Warn("User not found with this id")
+		return nil, model.ErrUserNotFound
+	}
+
+	if err := t.authenticationService.CheckLoginThrottle(user.Email, client.IP); err != nil {
+		log.Warn("Second factor throttled for user: " + userId)
+		return nil, err
+	}
+
+	if err := t.checkSecondFactor(userId, mfaVerify.Code); err != nil {
+		log.Warn("Invalid second factor for user: " + userId)
+		if err := t.loginHistoryService.Record(*user, client, model.LoginMethodTOTP, false); err != nil {
+			log.Warn("Error to record login failure: " + err.Error())
+		}
+		if errors.Is(err, model.ErrInvalidTOTP) {
+			return nil, t.registerSecondFactorFailure(*user, jti, expiresAt, client)
+		}
+		return nil, err
+	}
+
+	revoked, err = t.tokenRevocationStore.Revoke(jti, expiresAt)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrRevokeToken
+	}
+
+	if !revoked {
+		log.Warn("Mfa token used concurrently: " + jti)
+		return nil, model.ErrInvalidMFAToken
+	}
+
+	if err := t.loginAttemptStore.Reset(mfaAttemptKey(jti)); err != nil {
+		log.Warn("Error to reset mfa attempts: " + err.Error())
+	}
+
+	if err := t.authenticationService.ResetLoginFailures(user.Email); err != nil {
+		log.Warn("Error to reset login attempts: " + err.Error())
+	}
+
+	log.Info("Second factor verified successfully")
+	return t.authenticationService.IssueTokens(*user, client, model.LoginMethodTOTP, principal.Scopes)
+}
+
+// registerSecondFactorFailure feeds the account lockout shared with the
+// password login and burns the mfa token after too many wrong codes, so a
+// single password success does not buy unlimited guesses.
+func (t *totpService) registerSecondFactorFailure(user model.User, jti string, expiresAt time.Time, client model.ClientInfo) error {
+	log := slog.With(
+		slog.String("func", "registerSecondFactorFailure"),
+		slog.String("service", "totp"))
+
+	t.authenticationService.RegisterLoginFailure(user.Email, client.IP, &user)
+
+	attempt, err := t.loginAttemptStore.RegisterFailure(mfaAttemptKey(jti))
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	if attempt.Failures < mfaTokenMaxAttempts {
+		return model.ErrInvalidTOTP
+	}
+
+	if _, err := t.tokenRevocationStore.Revoke(jti, expiresAt); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrRevokeToken
+	}
+
+	log.Warn("Mfa token revoked after too many wrong codes: " + jti)
+	return model.ErrMFAAttemptsExceeded
+}
+
+func mfaAttemptKey(jti string) string {
+	return "mfa:" + jti
+}
+
+func (t *totpService) checkSecondFactor(userId string, code string) error {
+	userTOTP, err := t.totpRepository.GetByUserId(userId)
+	if err != nil {
+		return err
+	}
+
+	if userTOTP == nil || !userTOTP.Enabled {
+		return model.ErrTOTPNotEnabled
+	}
+
+	if err := t.useTOTPCode(*userTOTP, code); err == nil {
+		return nil
+	}
+
+	used, err := t.totpRepository.UseRecoveryCode(userId, util.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+
+	if !used {
+		return model.ErrInvalidTOTP
+	}
+
+	return nil
+}
+
+func (t *totpService) useTOTPCode(userTOTP model.UserTOTP, code string) error {
+	step, ok := util.ValidateTOTP(userTOTP.Secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return model.ErrInvalidTOTP
+	}
+
+	updated, err := t.totpRepository.UpdateLastUsedStep(userTOTP.UserId, step)
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		return model.ErrInvalidTOTP
+	}
+
+	return nil
+}
+
+func (t *totpService) generateRecoveryCodes(userId string) (*model.RecoveryCodes, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	codeHashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := util.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+		codeHashes = append(codeHashes, util.HashToken(code))
+	}
+
+	if err := t.totpRepository.ReplaceRecoveryCodes(userId, codeHashes); err != nil {
+		return nil, err
+	}
+
+	return &model.RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
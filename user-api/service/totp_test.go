@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OVillas/user-api/mocks"
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/repository"
+	"github.com/OVillas/user-api/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type totpVerifyFixture struct {
+	service           model.TOTPService
+	loginAttemptStore model.LoginAttemptStore
+	revocationStore   model.TokenRevocationStore
+	mfaToken          string
+	jti               string
+}
+
+func newTOTPVerifyFixture(t *testing.T) totpVerifyFixture {
+	t.Helper()
+	setupTokens(t)
+
+	user := model.User{Id: testUserId, Email: "ana@uerj.br"}
+
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetById", testUserId).Return(&user, nil)
+
+	totpRepository := mocks.NewTOTPRepository(t)
+	totpRepository.On("GetByUserId", testUserId).Return(&model.UserTOTP{UserId: testUserId, Secret: "JBSWY3DPEHPK3PXP", Enabled: true}, nil)
+	totpRepository.On("UseRecoveryCode", testUserId, mock.Anything).Return(false, nil)
+
+	loginHistoryService := mocks.NewLoginHistoryService(t)
+	loginHistoryService.On("Record", mock.Anything, mock.Anything, model.LoginMethodTOTP, false).Return(nil)
+
+	loginAttemptStore := repository.NewInMemoryLoginAttemptRepository(time.Hour)
+	revocationStore := repository.NewInMemoryRevokedTokenRepository()
+	authenticationService := &authenticationService{loginAttemptStore: loginAttemptStore}
+
+	mfaToken, err := util.CreateMFAToken(user, util.UserScopes(user))
+	require.NoError(t, err)
+
+	principal, err := util.ParseMFAToken(mfaToken)
+	require.NoError(t, err)
+
+	return totpVerifyFixture{
+		service:           NewTOTPService(userRepository, totpRepository, revocationStore, loginAttemptStore, authenticationService, loginHistoryService),
+		loginAttemptStore: loginAttemptStore,
+		revocationStore:   revocationStore,
+		mfaToken:          mfaToken,
+		jti:               principal.TokenId,
+	}
+}
+
+func TestTOTPVerifyFeedsLoginLockout(t *testing.T) {
+	fixture := newTOTPVerifyFixture(t)
+
+	_, err := fixture.service.Verify(model.MFAVerify{MFAToken: fixture.mfaToken, Code: "wrong"}, model.ClientInfo{IP: "203.0.113.7"})
+	assert.ErrorIs(t, err, model.ErrInvalidTOTP)
+
+	attempt, err := fixture.loginAttemptStore.Get(loginEmailKey("ana@uerj.br"))
+	require.NoError(t, err)
+	require.NotNil(t, attempt)
+	assert.Equal(t, 1, attempt.Failures)
+}
+
+func TestTOTPVerifyRevokesMFATokenAfterMaxAttempts(t *testing.T) {
+	fixture := newTOTPVerifyFixture(t)
+
+	for attempt := 1; attempt < mfaTokenMaxAttempts; attempt++ {
+		_, err := fixture.loginAttemptStore.RegisterFailure(mfaAttemptKey(fixture.jti))
+		require.NoError(t, err)
+	}
+
+	_, err := fixture.service.Verify(model.MFAVerify{MFAToken: fixture.mfaToken, Code: "wrong"}, model.ClientInfo{})
+	assert.ErrorIs(t, err, model.ErrMFAAttemptsExceeded)
+
+	revoked, err := fixture.revocationStore.IsRevoked(fixture.jti)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, err = fixture.service.Verify(model.MFAVerify{MFAToken: fixture.mfaToken, Code: "wrong"}, model.ClientInfo{})
+	assert.ErrorIs(t, err, model.ErrInvalidMFAToken)
+}
@@ -1,18 +1,44 @@
 package service
 
 import (
+	"errors"
 	"log/slog"
+	"slices"
+	"strconv"
 
 	"github.com/OVillas/user-api/model"
 )
 
 type userService struct {
-	userRepository model.UserRepository
+	userRepository         model.UserRepository
+	sessionRepository      model.SessionRepository
+	refreshTokenRepository model.RefreshTokenRepository
+	emailChangeRepository  model.EmailChangeRepository
+	emailService           model.EmailService
+	emailDomainPolicy      model.EmailDomainPolicy
+	enrollmentVerifier     model.EnrollmentVerifier
+	blobStore              model.BlobStore
 }
 
-func NewUserService(userRepository model.UserRepository) model.UserService {
+func NewUserService(
+	userRepository model.UserRepository,
+	sessionRepository model.SessionRepository,
+	refreshTokenRepository model.RefreshTokenRepository,
+	emailChangeRepository model.EmailChangeRepository,
+	emailService model.EmailService,
+	emailDomainPolicy model.EmailDomainPolicy,
+	enrollmentVerifier model.EnrollmentVerifier,
+	blobStore model.BlobStore,
+) model.UserService {
 	return userService{
-		userRepository: userRepository,
+		userRepository:         userRepository,
+		sessionRepository:      sessionRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		emailChangeRepository:  emailChangeRepository,
+		emailService:           emailService,
+		emailDomainPolicy:      emailDomainPolicy,
+		enrollmentVerifier:     enrollmentVerifier,
+		blobStore:              blobStore,
 	}
 }
 
@@ -32,6 +58,18 @@ func (us userService) Create(userPayLoad model.UserPayLoad) error {
 		return model.ErrUserAlreadyRegistered
 	}
 
+	if _, err := us.emailDomainPolicy.Affiliation(userPayLoad.Email); err != nil {
+		log.Warn("Email domain not allowed: " + userPayLoad.Email)
+		return err
+	}
+
+	if userPayLoad.Matricula != "" {
+		if err := us.checkMatricula(userPayLoad.Matricula); err != nil {
+			log.Warn("Matricula rejected: " + err.Error())
+			return err
+		}
+	}
+
 	hashedPassword, err := Hash(userPayLoad.Password)
 	if err != nil {
 		log.Error("Error trying to hashed password")
@@ -44,8 +82,18 @@ func (us userService) Create(userPayLoad model.UserPayLoad) error {
 		return model.ErrConvertUserPayLoadToUser
 	}
 
+	// The affiliation and the matricula are only verified once the email is
+	// confirmed, until then the account is external like any unverified address.
+	user.Affiliation = model.AffiliationExternal
+	if userPayLoad.Matricula != "" {
+		user.Matricula = &userPayLoad.Matricula
+	}
+
 	if err := us.userRepository.Create(*user); err != nil {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
+		if errors.Is(err, model.ErrMatriculaAlreadyRegistered) {
+			return err
+		}
 		return model.ErrCreateUser
 	}
 
@@ -60,7 +108,7 @@ func (us userService) GetAll() ([]model.UserResponse, error) {
 
 	users, err := us.userRepository.GetAll()
 	if err != nil {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return nil, model.ErrGetUser
 	}
 
@@ -71,7 +119,7 @@ func (us userService) GetAll() ([]model.UserResponse, error) {
 
 	var usersResponse []model.UserResponse
 	for _, user := range users {
-		usersResponse = append(usersResponse, *user.ToUserResponse())
+		usersResponse = append(usersResponse, *us.toUserResponse(user))
 	}
 
 	return usersResponse, nil
@@ -84,7 +132,7 @@ func (us userService) GetById(id string) (*model.UserResponse, error) {
 
 	user, err := us.userRepository.GetById(id)
 	if err != nil {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return nil, model.ErrGetUser
 	}
 
@@ -93,7 +141,7 @@ func (us userService) GetById(id string) (*model.UserResponse, error) {
 		return nil, nil
 	}
 
-	userResponse := user.ToUserResponse()
+	userResponse := us.toUserResponse(*user)
 
 	return userResponse, err
 }
@@ -105,7 +153,7 @@ func (us userService) GetByName(name string) ([]model.UserResponse, error) {
 
 	users, err := us.userRepository.GetByName(name)
 	if err != nil {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return nil, model.ErrGetUser
 	}
 
@@ -116,7 +164,7 @@ func (us userService) GetByName(name string) ([]model.UserResponse, error) {
 
 	var usersResponse []model.UserResponse
 	for _, user := range users {
-		usersResponse = append(usersResponse, *user.ToUserResponse())
+		usersResponse = append(usersResponse, *us.toUserResponse(user))
 	}
 
 	return usersResponse, err
@@ -129,7 +177,7 @@ func (us userService) GetByEmail(email string) (*model.UserResponse, error) {
 
 	user, err := us.userRepository.GetByEmail(email)
 	if err != nil {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return nil, model.ErrGetUser
 	}
 
@@ -138,7 +186,7 @@ func (us userService) GetByEmail(email string) (*model.UserResponse, error) {
 		return nil, nil
 	}
 
-	userResponse := user.ToUserResponse()
+	userResponse := us.toUserResponse(*user)
 
 	return userResponse, nil
 }
@@ -150,7 +198,7 @@ func (us userService) Update(id string, userUpdate model.UserUpdatePayLoad) erro
 
 	user, err := us.userRepository.GetById(id)
 	if err != nil {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return model.ErrGetUser
 	}
 
@@ -164,17 +212,54 @@ func (us userService) Update(id string, userUpdate model.UserUpdatePayLoad) erro
 		return model.ErrSameEmail
 	}
 
+	// Everything is validated before the first write, so a rejected field
+	// does not leave the others half applied.
+	if userUpdate.Name != "" {
+		user.Name = userUpdate.Name
+	}
+
+	matriculaChanged := userUpdate.Matricula != "" && (user.Matricula == nil || *user.Matricula != userUpdate.Matricula)
+	if matriculaChanged {
+		if err := us.checkMatricula(userUpdate.Matricula); err != nil {
+			log.Warn("Matricula rejected: " + err.Error())
+			return err
+		}
+		user.Matricula = &userUpdate.Matricula
+		user.MatriculaVerified = false
+	}
+
+	attempts := 0
 	if userUpdate.Email != "" {
-		user.Email = userUpdate.Email
+		attempts, err = us.checkEmailChange(*user, userUpdate.Email)
+		if err != nil {
+			log.Warn("Email change rejected: " + err.Error())
+			return err
+		}
 	}
 
-	if userUpdate.Name != "" {
-		user.Name = userUpdate.Name
+	if userUpdate.Name != "" || userUpdate.Matricula != "" {
+		if err := us.userRepository.Update(id, *user); err != nil {
+			log.Error("Error: " + err.Error())
+			if errors.Is(err, model.ErrMatriculaAlreadyRegistered) {
+				return err
+			}
+			return model.ErrCreateUser
+		}
 	}
 
-	if err := us.userRepository.Update(id, *user); err != nil {
-		log.Error("Error: ", err)
-		return model.ErrCreateUser
+	// A new email is only checked once its change is confirmed, so the
+	// matricula is verified here against the email already confirmed.
+	if matriculaChanged && user.IsEmailConfirmed {
+		verifyMatricula(us.enrollmentVerifier, us.userRepository, *user, user.Email)
+	}
+
+	if userUpdate.Email == "" {
+		return nil
+	}
+
+	if err := us.requestEmailChange(*user, userUpdate.Email, attempts); err != nil {
+		log.Warn("Error to request email change: " + err.Error())
+		return err
 	}
 
 	return nil
@@ -197,9 +282,108 @@ func (us userService) Delete(id string) error {
 	}
 
 	if err := us.userRepository.Delete(id); err != nil {
-		log.Error("Error: ", err)
+		log.Error("Error: " + err.Error())
 		return model.ErrDeleteUser
 	}
 
 	return nil
 }
+
+func (us userService) UpdateRole(principalId string, id string, role string) error {
+	log := slog.With(
+		slog.String("service", "user"),
+		slog.String("func", "UpdateRole"))
+
+	if !slices.Contains(model.Roles, role) {
+		log.Warn("Invalid role: " + role)
+		return model.ErrInvalidRole
+	}
+
+	if principalId == id {
+		log.Warn("Admin tried to change their own role: " + id)
+		return model.ErrChangeOwnRole
+	}
+
+	user, err := us.userRepository.GetById(id)
+	if err != nil {
+		log.Error("Error trying to get user from repository")
+		return model.ErrGetUser
+	}
+
+	if user == nil {
+		log.Warn("User not found to update role")
+		return model.ErrUserNotFound
+	}
+
+	if user.Role == role {
+		return nil
+	}
+
+	if user.Role == model.RoleAdmin {
+		admins, err := us.userRepository.CountByRole(model.RoleAdmin)
+		if err != nil {
+			log.Error("Error: " + err.Error())
+			return model.ErrUpdateRole
+		}
+
+		if admins <= 1 {
+			log.Warn("Refused to demote the last admin: " + id)
+			return model.ErrLastAdmin
+		}
+	}
+
+	if err := us.userRepository.UpdateRole(id, role); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrUpdateRole
+	}
+
+	// Access and refresh tokens carry the role they were issued with, so the
+	// user signs in again to get the new one.
+	if err := us.sessionRepository.RevokeAllForUser(id, ""); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrRevokeSession
+	}
+
+	if err := us.refreshTokenRepository.RevokeAllForUser(id, ""); err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrRevokeSession
+	}
+
+	log.Info("success to update user role")
+	return nil
+}
+
+// checkMatricula only rejects a matricula that is already taken. One missing
+// from the registrar records is still stored, it is just never verified.
+func (us userService) checkMatricula(matricula string) error {
+	log := slog.With(
+		slog.String("service", "user"),
+		slog.String("func", "checkMatricula"))
+
+	registeredUser, err := us.userRepository.GetByMatricula(matricula)
+	if err != nil {
+		log.Error("Error trying to get user from repository")
+		return model.ErrGetUser
+	}
+
+	if registeredUser != nil {
+		log.Warn("There is already a registered user with this matricula")
+		return model.ErrMatriculaAlreadyRegistered
+	}
+
+	return nil
+}
+
+func (us userService) toUserResponse(user model.User) *model.UserResponse {
+	userResponse := user.ToUserResponse()
+	if user.AvatarId == nil {
+		return userResponse
+	}
+
+	userResponse.AvatarURLs = map[string]string{}
+	for _, size := range model.AvatarSizes {
+		userResponse.AvatarURLs[strconv.Itoa(size)] = us.blobStore.URL(model.AvatarKey(user.Id, *user.AvatarId, size))
+	}
+
+	return userResponse
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/OVillas/user-api/config"
+	"github.com/OVillas/user-api/mocks"
+	"github.com/OVillas/user-api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateLeavesAffiliationExternalUntilConfirmed(t *testing.T) {
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetByEmail", "ana@uerj.br").Return(nil, nil).Once()
+	userRepository.On("Create", mock.MatchedBy(func(user model.User) bool {
+		return user.Affiliation == model.AffiliationExternal
+	})).Return(nil).Once()
+
+	us := userService{
+		userRepository:    userRepository,
+		emailDomainPolicy: NewEmailDomainPolicy([]config.EmailDomainRule{{Domain: "uerj.br", Affiliation: model.AffiliationStaff}}, model.EmailDomainPolicyFlag),
+	}
+
+	err := us.Create(model.UserPayLoad{Name: "Ana", Email: "ana@uerj.br", Password: "senha!123"})
+	assert.NoError(t, err)
+}
+
+func TestUpdateWritesNothingWhenEmailChangeIsRejected(t *testing.T) {
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetById", testUserId).Return(&model.User{Id: testUserId, Name: "Ana Souza", Email: "ana@uerj.br"}, nil).Once()
+	userRepository.On("GetByMatricula", "202310012345").Return(nil, nil).Once()
+	userRepository.On("GetByEmail", "bruno@uerj.br").Return(&model.User{Id: "other"}, nil).Once()
+
+	us := userService{
+		userRepository:     userRepository,
+		emailDomainPolicy:  NewEmailDomainPolicy([]config.EmailDomainRule{{Domain: "uerj.br", Affiliation: model.AffiliationStaff}}, model.EmailDomainPolicyFlag),
+		enrollmentVerifier: NewFakeEnrollmentVerifier(model.EnrollmentRecord{Matricula: "202310012345", Email: "ana@uerj.br"}),
+	}
+
+	err := us.Update(testUserId, model.UserUpdatePayLoad{Name: "Ana S.", Email: "bruno@uerj.br", Matricula: "202310012345"})
+	assert.ErrorIs(t, err, model.ErrUserAlreadyRegistered)
+	userRepository.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUpdateMapsConcurrentMatriculaToConflict(t *testing.T) {
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetById", testUserId).Return(&model.User{Id: testUserId, Name: "Ana Souza", Email: "ana@uerj.br"}, nil).Once()
+	userRepository.On("GetByMatricula", "202310012345").Return(nil, nil).Once()
+	userRepository.On("Update", testUserId, mock.MatchedBy(func(user model.User) bool {
+		return user.Matricula != nil && *user.Matricula == "202310012345"
+	})).Return(model.ErrMatriculaAlreadyRegistered).Once()
+
+	us := userService{
+		userRepository:     userRepository,
+		enrollmentVerifier: NewFakeEnrollmentVerifier(model.EnrollmentRecord{Matricula: "202310012345", Email: "ana@uerj.br"}),
+	}
+
+	err := us.Update(testUserId, model.UserUpdatePayLoad{Matricula: "202310012345"})
+	assert.ErrorIs(t, err, model.ErrMatriculaAlreadyRegistered)
+}
+
+func TestCreateStoresMatriculaUnverified(t *testing.T) {
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetByEmail", "ana@uerj.br").Return(nil, nil).Once()
+	userRepository.On("GetByMatricula", "202310012345").Return(nil, nil).Once()
+	userRepository.On("Create", mock.MatchedBy(func(user model.User) bool {
+		return user.Matricula != nil && *user.Matricula == "202310012345" && !user.MatriculaVerified
+	})).Return(nil).Once()
+
+	us := userService{
+		userRepository:     userRepository,
+		emailDomainPolicy:  NewEmailDomainPolicy([]config.EmailDomainRule{{Domain: "uerj.br", Affiliation: model.AffiliationStaff}}, model.EmailDomainPolicyFlag),
+		enrollmentVerifier: NewFakeEnrollmentVerifier(model.EnrollmentRecord{Matricula: "202310012345", Email: "ana@uerj.br"}),
+	}
+
+	err := us.Create(model.UserPayLoad{Name: "Ana", Email: "ana@uerj.br", Password: "senha!123", Matricula: "202310012345"})
+	assert.NoError(t, err)
+}
+
+func TestUpdateMatricula(t *testing.T) {
+	oldMatricula := "202310099999"
+
+	testCases := []struct {
+		name             string
+		user             model.User
+		matricula        string
+		verifiedStored   bool
+		expectedVerified bool
+	}{
+		{
+			name:      "unconfirmed email is not checked",
+			user:      model.User{Id: testUserId, Name: "Ana Souza", Email: "ana@uerj.br"},
+			matricula: "202310012345",
+		},
+		{
+			name:             "confirmed email in the records",
+			user:             model.User{Id: testUserId, Name: "Ana Souza", Email: "ana@uerj.br", IsEmailConfirmed: true},
+			matricula:        "202310012345",
+			verifiedStored:   true,
+			expectedVerified: true,
+		},
+		{
+			name:      "matricula of someone else is kept unverified",
+			user:      model.User{Id: testUserId, Name: "Ana Souza", Email: "ana@uerj.br", IsEmailConfirmed: true},
+			matricula: "202310054321",
+		},
+		{
+			name:      "replaced matricula loses the old verification",
+			user:      model.User{Id: testUserId, Name: "Ana Souza", Email: "ana@uerj.br", Matricula: &oldMatricula, MatriculaVerified: true},
+			matricula: "202310054321",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			user := testCase.user
+
+			userRepository := mocks.NewUserRepository(t)
+			userRepository.On("GetById", testUserId).Return(&user, nil).Once()
+			userRepository.On("GetByMatricula", testCase.matricula).Return(nil, nil).Once()
+			userRepository.On("Update", testUserId, mock.MatchedBy(func(user model.User) bool {
+				return *user.Matricula == testCase.matricula && !user.MatriculaVerified
+			})).Return(nil).Once()
+			if testCase.verifiedStored {
+				userRepository.On("UpdateMatriculaVerified", testUserId, testCase.expectedVerified).Return(nil).Once()
+			}
+
+			us := userService{
+				userRepository: userRepository,
+				enrollmentVerifier: NewFakeEnrollmentVerifier(
+					model.EnrollmentRecord{Matricula: "202310012345", Email: "ana@uerj.br"},
+					model.EnrollmentRecord{Matricula: "202310054321", Email: "bruno@uerj.br"},
+				),
+			}
+
+			assert.NoError(t, us.Update(testUserId, model.UserUpdatePayLoad{Matricula: testCase.matricula}))
+		})
+	}
+}
+
+const testAdminId = "0b7e5d3c-1a2f-4e6d-8c9b-7a5e3f1d2c4b"
+
+func TestUpdateRoleRejectsUnknownRole(t *testing.T) {
+	us := userService{userRepository: mocks.NewUserRepository(t)}
+
+	err := us.UpdateRole(testAdminId, testUserId, "superuser")
+	assert.ErrorIs(t, err, model.ErrInvalidRole)
+}
+
+func TestUpdateRoleRejectsOwnRole(t *testing.T) {
+	us := userService{userRepository: mocks.NewUserRepository(t)}
+
+	err := us.UpdateRole(testAdminId, testAdminId, model.RoleUser)
+	assert.ErrorIs(t, err, model.ErrChangeOwnRole)
+}
+
+func TestUpdateRoleKeepsLastAdmin(t *testing.T) {
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetById", testUserId).Return(&model.User{Id: testUserId, Role: model.RoleAdmin}, nil).Once()
+	userRepository.On("CountByRole", model.RoleAdmin).Return(int64(1), nil).Once()
+
+	us := userService{userRepository: userRepository}
+
+	err := us.UpdateRole(testAdminId, testUserId, model.RoleUser)
+	assert.ErrorIs(t, err, model.ErrLastAdmin)
+	userRepository.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything)
+}
+
+func TestUpdateRoleRevokesSessionsOfTarget(t *testing.T) {
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetById", testUserId).Return(&model.User{Id: testUserId, Role: model.RoleAdmin}, nil).Once()
+	userRepository.On("CountByRole", model.RoleAdmin).Return(int64(2), nil).Once()
+	userRepository.On("UpdateRole", testUserId, model.RoleUser).Return(nil).Once()
+
+	sessionRepository := mocks.NewSessionRepository(t)
+	sessionRepository.On("RevokeAllForUser", testUserId, "").Return(nil).Once()
+
+	refreshTokenRepository := mocks.NewRefreshTokenRepository(t)
+	refreshTokenRepository.On("RevokeAllForUser", testUserId, "").Return(nil).Once()
+
+	us := userService{
+		userRepository:         userRepository,
+		sessionRepository:      sessionRepository,
+		refreshTokenRepository: refreshTokenRepository,
+	}
+
+	assert.NoError(t, us.UpdateRole(testAdminId, testUserId, model.RoleUser))
+}
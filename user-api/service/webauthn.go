@@ -0,0 +1,379 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/OVillas/user-api/util"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const webAuthnSessionExpiry = 5 * time.Minute
+
+type webAuthnSession struct {
+	userId     string
+	data       webauthn.SessionData
+	expiryTime time.Time
+}
+
+type webAuthnUser struct {
+	user        model.User
+	credentials []webauthn.Credential
+}
+
+func (wu *webAuthnUser) WebAuthnID() []byte {
+	return []byte(wu.user.Id)
+}
+
+func (wu *webAuthnUser) WebAuthnName() string {
+	return wu.user.Email
+}
+
+func (wu *webAuthnUser) WebAuthnDisplayName() string {
+	return wu.user.Name
+}
+
+func (wu *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return wu.credentials
+}
+
+func (wu *webAuthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+type webAuthnService struct {
+	webAuthn                     *webauthn.WebAuthn
+	userRepository               model.UserRepository
+	webAuthnCredentialRepository model.WebAuthnCredentialRepository
+	authenticationService        model.AuthenticationService
+	mutex                        sync.Mutex
+	sessions                     map[string]webAuthnSession
+}
+
+func NewWebAuthnService(
+	rpID string,
+	rpOrigins []string,
+	userRepository model.UserRepository,
+	webAuthnCredentialRepository model.WebAuthnCredentialRepository,
+	authenticationService model.AuthenticationService,
+) (model.WebAuthnService, error) {
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          rpID,
+		RPDisplayName: totpIssuer,
+		RPOrigins:     rpOrigins,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &webAuthnService{
+		webAuthn:                     webAuthn,
+		userRepository:               userRepository,
+		webAuthnCredentialRepository: webAuthnCredentialRepository,
+		authenticationService:        authenticationService,
+		sessions:                     make(map[string]webAuthnSession),
+	}, nil
+}
+
+func (ws *webAuthnService) BeginRegistration(userId string) (*model.WebAuthnCeremony, error) {
+	log := slog.With(
+		slog.String("func", "BeginRegistration"),
+		slog.String("service", "webAuthn"))
+
+	user, err := ws.loadUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.credentials))
+	for _, credential := range user.credentials {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	creation, session, err := ws.webAuthn.BeginRegistration(
+		user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrWebAuthnRegistration
+	}
+
+	sessionId, err := ws.saveSession(userId, *session)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, model.ErrWebAuthnRegistration
+	}
+
+	log.Info("Webauthn registration started successfully")
+	return &model.WebAuthnCeremony{SessionId: sessionId, Options: creation}, nil
+}
+
+func (ws *webAuthnService) FinishRegistration(userId string, sessionId string, body io.Reader) error {
+	log := slog.With(
+		slog.String("func", "FinishRegistration"),
+		slog.String("service", "webAuthn"))
+
+	session, ok := ws.popSession(sessionId)
+	if !ok || session.userId != userId {
+		log.Warn("Webauthn session not found for user: " + userId)
+		return model.ErrWebAuthnSessionNotFound
+	}
+
+	user, err := ws.loadUser(userId)
+	if err != nil {
+		return err
+	}
+
+	parsedResponse, err := protocol.ParseCredentialCreationResponseBody(body)
+	if err != nil {
+		log.Warn("Error: " + err.Error())
+		return model.ErrWebAuthnRegistration
+	}
+
+	credential, err := ws.webAuthn.CreateCredential(user, session.data, parsedResponse)
+	if err != nil {
+		log.Warn("Error: " + err.Error())
+		return model.ErrWebAuthnRegistration
+	}
+
+	credentialId := base64.RawURLEncoding.EncodeToString(credential.ID)
+
+	existing, err := ws.webAuthnCredentialRepository.GetById(credentialId)
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return err
+	}
+
+	if existing != nil {
+		log.Warn("Webauthn credential already registered: " + credentialId)
+		return model.ErrWebAuthnCredentialExists
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	err = ws.webAuthnCredentialRepository.Create(model.WebAuthnCredential{
+		Id:              credentialId,
+		UserId:          userId,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transport:       strings.Join(transports, ","),
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+	})
+	if err != nil {
+		log.Error("Error: " + err.Error())
+		return model.ErrWebAuthnRegistration
+	}
+
+	log.Info("Webauthn credential registered successfully")
+	return nil
+}
+
+func (ws *webAuthnService) BeginLogin(email string) (*model.WebAuthnCeremony, error) {
+	log := slog.With(
+		slog.String("func", "BeginLogin"),
+		slog.String("service", "webAuthn"))
+
+	if email == "" {
+		assertion, session, err := ws.webAuthn.BeginDiscoverableLogin()
+		if err != nil {
+			log.Error("Error: " + err.Error())
+			return nil, model.ErrWebAuthnLogin
+		}
+
+		return ws.loginCeremony("", *session, assertion)
+	}
+
+	user, err := ws.userRepository.GetByEmail(email)
+	if err != nil {
+		log.Warn("Failed to obtain user by email")
+		return nil, model.ErrGetUser
+	}
+
+	if user == nil {
+		log.Warn("User not found with this email: " + email)
+		return nil, model.ErrWebAuthnLogin
+	}
+
+	webAuthnUser, err := ws.loadUser(user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	assertion, session, err := ws.webAuthn.BeginLogin(webAuthnUser)
+	if err != nil {
+		log.Warn("Error: " + err.Error())
+		return nil, model.ErrWebAuthnLogin
+	}
+
+	return ws.loginCeremony(user.Id, *session, assertion)
+}
+
+func (ws *webAuthnService) FinishLogin(sessionId string, body io.Reader, client model.ClientInfo) (*model.TokenPair, error) {
+	log := slog.With(
+		slog.String("func", "FinishLogin"),
+		slog.String("service", "webAuthn"))
+
+	session, ok := ws.popSession(sessionId)
+	if !ok {
+		log.Warn("Webauthn session not found")
+		return nil, model.ErrWebAuthnSessionNotFound
+	}
+
+	parsedResponse, err := protocol.ParseCredentialRequestResponseBody(body)
+	if err != nil {
+		log.Warn("Error: " + err.Error())
+		return nil, model.ErrWebAuthnLogin
+	}
+
+	var user *webAuthnUser
+	var credential *webauthn.Credential
+
+	if session.userId != "" {
+		user, err = ws.loadUser(session.userId)
+		if err != nil {
+			return nil, err
+		}
+
+		credential, err = ws.webAuthn.ValidateLogin(user, session.data, parsedResponse)
+	} else {
+		credential, err = ws.webAuthn.ValidateDiscoverableLogin(func(rawID []byte, userHandle []byte) (webauthn.User, error) {
+			user, err = ws.loadUser(string(userHandle))
+			return user, err
+		}, session.data, parsedResponse)
+	}
+
+	if err != nil {
+		log.Warn("Error: " + err.Error())
+		return nil, model.ErrWebAuthnLogin
+	}
+
+	if credential.Authenticator.CloneWarning {
+		log.Warn("Webauthn sign count did not increase for user: " + user.user.Id)
+		return nil, model.ErrWebAuthnCloneWarning
+	}
+
+	credentialId := base64.RawURLEncoding.EncodeToString(credential.ID)
+	if err := ws.webAuthnCredentialRepository.UpdateSignCount(credentialId, credential.Authenticator.SignCount); err != nil {
+		log.Error("Error: " + err.Error())
+		return nil, err
+	}
+
+	log.Info("Webauthn login executed successfully")
+	return ws.authenticationService.IssueTokens(user.user, client, model.LoginMethodWebAuthn, nil)
+}
+
+func (ws *webAuthnService) loginCeremony(userId string, session webauthn.SessionData, assertion *protocol.CredentialAssertion) (*model.WebAuthnCeremony, error) {
+	sessionId, err := ws.saveSession(userId, session)
+	if err != nil {
+		return nil, model.ErrWebAuthnLogin
+	}
+
+	return &model.WebAuthnCeremony{SessionId: sessionId, Options: assertion}, nil
+}
+
+func (ws *webAuthnService) loadUser(userId string) (*webAuthnUser, error) {
+	user, err := ws.userRepository.GetById(userId)
+	if err != nil {
+		return nil, model.ErrGetUser
+	}
+
+	if user == nil {
+		return nil, model.ErrUserNotFound
+	}
+
+	storedCredentials, err := ws.webAuthnCredentialRepository.GetByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]webauthn.Credential, 0, len(storedCredentials))
+	for _, storedCredential := range storedCredentials {
+		credential, err := toWebAuthnCredential(storedCredential)
+		if err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, *credential)
+	}
+
+	return &webAuthnUser{user: *user, credentials: credentials}, nil
+}
+
+func (ws *webAuthnService) saveSession(userId string, data webauthn.SessionData) (string, error) {
+	sessionId, err := util.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	now := time.Now()
+	for key, session := range ws.sessions {
+		if now.After(session.expiryTime) {
+			delete(ws.sessions, key)
+		}
+	}
+
+	ws.sessions[sessionId] = webAuthnSession{
+		userId:     userId,
+		data:       data,
+		expiryTime: now.Add(webAuthnSessionExpiry),
+	}
+
+	return sessionId, nil
+}
+
+func (ws *webAuthnService) popSession(sessionId string) (webAuthnSession, bool) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	session, ok := ws.sessions[sessionId]
+	if !ok {
+		return webAuthnSession{}, false
+	}
+
+	delete(ws.sessions, sessionId)
+	if time.Now().After(session.expiryTime) {
+		return webAuthnSession{}, false
+	}
+
+	return session, true
+}
+
+func toWebAuthnCredential(storedCredential model.WebAuthnCredential) (*webauthn.Credential, error) {
+	id, err := base64.RawURLEncoding.DecodeString(storedCredential.Id)
+	if err != nil {
+		return nil, errors.Join(model.ErrWebAuthnLogin, err)
+	}
+
+	var transports []protocol.AuthenticatorTransport
+	if storedCredential.Transport != "" {
+		for _, transport := range strings.Split(storedCredential.Transport, ",") {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+	}
+
+	return &webauthn.Credential{
+		ID:              id,
+		PublicKey:       storedCredential.PublicKey,
+		AttestationType: storedCredential.AttestationType,
+		Transport:       transports,
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    storedCredential.AAGUID,
+			SignCount: storedCredential.SignCount,
+		},
+	}, nil
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/OVillas/user-api/mocks"
+	"github.com/OVillas/user-api/model"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRPID     = "localhost"
+	testRPOrigin = "http://localhost"
+)
+
+// testAuthenticator plays the part of a passkey: it answers registration and
+// login ceremonies with a P-256 key and "none" attestation.
+type testAuthenticator struct {
+	t            *testing.T
+	credentialId []byte
+	privateKey   *ecdsa.PrivateKey
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	credentialId := make([]byte, 16)
+	_, err = rand.Read(credentialId)
+	require.NoError(t, err)
+
+	return &testAuthenticator{t: t, credentialId: credentialId, privateKey: privateKey}
+}
+
+func (ta *testAuthenticator) authenticatorData(flags protocol.AuthenticatorFlags, signCount uint32, attestedCredentialData []byte) []byte {
+	rpIdHash := sha256.Sum256([]byte(testRPID))
+
+	data := append([]byte{}, rpIdHash[:]...)
+	data = append(data, byte(flags))
+	data = binary.BigEndian.AppendUint32(data, signCount)
+	return append(data, attestedCredentialData...)
+}
+
+func (ta *testAuthenticator) clientData(ceremony protocol.CeremonyType, challenge protocol.URLEncodedBase64) []byte {
+	clientData, err := json.Marshal(protocol.CollectedClientData{
+		Type:      ceremony,
+		Challenge: challenge.String(),
+		Origin:    testRPOrigin,
+	})
+	require.NoError(ta.t, err)
+
+	return clientData
+}
+
+func (ta *testAuthenticator) register(ceremony *model.WebAuthnCeremony) []byte {
+	creation := ceremony.Options.(*protocol.CredentialCreation)
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: ta.privateKey.X.FillBytes(make([]byte, 32)),
+		YCoord: ta.privateKey.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(ta.t, err)
+
+	attestedCredentialData := make([]byte, 16)
+	attestedCredentialData = binary.BigEndian.AppendUint16(attestedCredentialData, uint16(len(ta.credentialId)))
+	attestedCredentialData = append(attestedCredentialData, ta.credentialId...)
+	attestedCredentialData = append(attestedCredentialData, publicKey...)
+
+	attestationObject, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": ta.authenticatorData(protocol.FlagUserPresent|protocol.FlagAttestedCredentialData, 0, attestedCredentialData),
+	})
+	require.NoError(ta.t, err)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"id":    base64.RawURLEncoding.EncodeToString(ta.credentialId),
+		"rawId": base64.RawURLEncoding.EncodeToString(ta.credentialId),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(ta.clientData(protocol.CreateCeremony, creation.Response.Challenge)),
+			"attestationObject": base64.RawURLEncoding.EncodeToString(attestationObject),
+		},
+	})
+	require.NoError(ta.t, err)
+
+	return body
+}
+
+func (ta *testAuthenticator) assert(ceremony *model.WebAuthnCeremony, userId string, signCount uint32) []byte {
+	assertion := ceremony.Options.(*protocol.CredentialAssertion)
+
+	authenticatorData := ta.authenticatorData(protocol.FlagUserPresent, signCount, nil)
+	clientData := ta.clientData(protocol.AssertCeremony, assertion.Response.Challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authenticatorData...), clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, ta.privateKey, digest[:])
+	require.NoError(ta.t, err)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"id":    base64.RawURLEncoding.EncodeToString(ta.credentialId),
+		"rawId": base64.RawURLEncoding.EncodeToString(ta.credentialId),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientData),
+			"authenticatorData": base64.RawURLEncoding.EncodeToString(authenticatorData),
+			"signature":         base64.RawURLEncoding.EncodeToString(signature),
+			"userHandle":        base64.RawURLEncoding.EncodeToString([]byte(userId)),
+		},
+	})
+	require.NoError(ta.t, err)
+
+	return body
+}
+
+type webAuthnFixture struct {
+	service                      model.WebAuthnService
+	authenticator                *testAuthenticator
+	userRepository               *mocks.UserRepository
+	webAuthnCredentialRepository *mocks.WebAuthnCredentialRepository
+	authenticationService        *mocks.AuthenticationService
+	user                         model.User
+}
+
+func newWebAuthnFixture(t *testing.T) webAuthnFixture {
+	fixture := webAuthnFixture{
+		authenticator:                newTestAuthenticator(t),
+		userRepository:               mocks.NewUserRepository(t),
+		webAuthnCredentialRepository: mocks.NewWebAuthnCredentialRepository(t),
+		authenticationService:        mocks.NewAuthenticationService(t),
+		user:                         model.User{Id: testUserId, Name: "Ana", Email: "ana@uerj.br"},
+	}
+
+	fixture.userRepository.On("GetById", testUserId).Return(&fixture.user, nil).Maybe()
+	fixture.userRepository.On("GetByEmail", "ana@uerj.br").Return(&fixture.user, nil).Maybe()
+
+	service, err := NewWebAuthnService(testRPID, []string{testRPOrigin}, fixture.userRepository, fixture.webAuthnCredentialRepository, fixture.authenticationService)
+	require.NoError(t, err)
+	fixture.service = service
+
+	return fixture
+}
+
+func (wf webAuthnFixture) storedCredential(t *testing.T, signCount uint32) model.WebAuthnCredential {
+	var stored model.WebAuthnCredential
+	wf.webAuthnCredentialRepository.On("GetByUserId", testUserId).Return([]model.WebAuthnCredential{}, nil).Twice()
+	wf.webAuthnCredentialRepository.On("GetById", mock.Anything).Return(nil, nil).Once()
+	wf.webAuthnCredentialRepository.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(model.WebAuthnCredential)
+	}).Return(nil).Once()
+
+	ceremony, err := wf.service.BeginRegistration(testUserId)
+	require.NoError(t, err)
+	require.NoError(t, wf.service.FinishRegistration(testUserId, ceremony.SessionId, bytes.NewReader(wf.authenticator.register(ceremony))))
+
+	stored.SignCount = signCount
+	return stored
+}
+
+func TestWebAuthnRegistrationStoresCredential(t *testing.T) {
+	fixture := newWebAuthnFixture(t)
+
+	stored := fixture.storedCredential(t, 0)
+
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(fixture.authenticator.credentialId), stored.Id)
+	assert.Equal(t, testUserId, stored.UserId)
+	assert.NotEmpty(t, stored.PublicKey)
+}
+
+func TestWebAuthnLoginUpdatesSignCount(t *testing.T) {
+	fixture := newWebAuthnFixture(t)
+	stored := fixture.storedCredential(t, 4)
+
+	fixture.webAuthnCredentialRepository.On("GetByUserId", testUserId).Return([]model.WebAuthnCredential{stored}, nil)
+	fixture.webAuthnCredentialRepository.On("UpdateSignCount", stored.Id, uint32(5)).Return(nil).Once()
+	fixture.authenticationService.On("IssueTokens", fixture.user, model.ClientInfo{}, model.LoginMethodWebAuthn, []string(nil)).
+		Return(&model.TokenPair{AccessToken: "access-token"}, nil).Once()
+
+	ceremony, err := fixture.service.BeginLogin("ana@uerj.br")
+	require.NoError(t, err)
+
+	tokenPair, err := fixture.service.FinishLogin(ceremony.SessionId, bytes.NewReader(fixture.authenticator.assert(ceremony, testUserId, 5)), model.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, "access-token", tokenPair.AccessToken)
+}
+
+func TestWebAuthnLoginRejectsCloneWarning(t *testing.T) {
+	fixture := newWebAuthnFixture(t)
+	stored := fixture.storedCredential(t, 5)
+
+	fixture.webAuthnCredentialRepository.On("GetByUserId", testUserId).Return([]model.WebAuthnCredential{stored}, nil)
+
+	ceremony, err := fixture.service.BeginLogin("")
+	require.NoError(t, err)
+
+	_, err = fixture.service.FinishLogin(ceremony.SessionId, bytes.NewReader(fixture.authenticator.assert(ceremony, testUserId, 5)), model.ClientInfo{})
+	assert.ErrorIs(t, err, model.ErrWebAuthnCloneWarning)
+	fixture.webAuthnCredentialRepository.AssertNotCalled(t, "UpdateSignCount", mock.Anything, mock.Anything)
+	fixture.authenticationService.AssertNotCalled(t, "IssueTokens", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebAuthnFinishLoginRejectsUnknownSession(t *testing.T) {
+	fixture := newWebAuthnFixture(t)
+
+	_, err := fixture.service.FinishLogin("unknown", bytes.NewReader(nil), model.ClientInfo{})
+	assert.ErrorIs(t, err, model.ErrWebAuthnSessionNotFound)
+}
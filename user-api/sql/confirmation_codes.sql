@@ -0,0 +1,8 @@
+CREATE TABLE ConfirmationCodes
+(
+    Email      VARCHAR(100) PRIMARY KEY,
+    Code       VARCHAR(6)   NOT NULL,
+    ExpiryTime TIMESTAMP    NOT NULL,
+    Attempts   INT          NOT NULL DEFAULT 0,
+    INDEX idx_confirmation_codes_expiry_time (ExpiryTime)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE EmailChangeRequests
+(
+    UserId          CHAR(36) PRIMARY KEY,
+    NewEmail        VARCHAR(100) NOT NULL,
+    CodeHash        CHAR(64)     NOT NULL,
+    CancelTokenHash CHAR(64)     NOT NULL UNIQUE,
+    Attempts        INT          NOT NULL DEFAULT 0,
+    ExpiresAt       TIMESTAMP    NOT NULL,
+    CreatedAt       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (UserId) REFERENCES Users (Id) ON DELETE CASCADE
+);
@@ -0,0 +1,8 @@
+CREATE TABLE LoginAttempts
+(
+    `Key`         VARCHAR(150) PRIMARY KEY,
+    Failures      INT          NOT NULL DEFAULT 0,
+    LastFailureAt TIMESTAMP    NOT NULL,
+    LockedUntil   TIMESTAMP    NULL,
+    INDEX idx_login_attempts_last_failure_at (LastFailureAt)
+);
@@ -0,0 +1,14 @@
+CREATE TABLE LoginHistory
+(
+    Id                CHAR(36) PRIMARY KEY,
+    UserId            CHAR(36)     NOT NULL,
+    Method            VARCHAR(20)  NOT NULL,
+    Success           BOOLEAN      NOT NULL,
+    IP                VARCHAR(45)  NOT NULL,
+    UserAgent         VARCHAR(512) NOT NULL,
+    DeviceFingerprint CHAR(64)     NOT NULL,
+    CreatedAt         TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_login_history_user (UserId, CreatedAt),
+    INDEX idx_login_history_device (UserId, DeviceFingerprint),
+    FOREIGN KEY (UserId) REFERENCES Users (Id) ON DELETE CASCADE
+);
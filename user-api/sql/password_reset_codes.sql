@@ -0,0 +1,9 @@
+CREATE TABLE PasswordResetCodes
+(
+    Email      VARCHAR(100) PRIMARY KEY,
+    Code       VARCHAR(6)   NOT NULL,
+    ExpiryTime TIMESTAMP    NOT NULL,
+    Attempts   INT          NOT NULL DEFAULT 0,
+    LastSentAt TIMESTAMP    NOT NULL,
+    INDEX idx_password_reset_codes_expiry_time (ExpiryTime)
+);
@@ -0,0 +1,14 @@
+CREATE TABLE PersonalAccessTokens
+(
+    Id         CHAR(36) PRIMARY KEY,
+    UserId     CHAR(36)     NOT NULL,
+    Name       VARCHAR(100) NOT NULL,
+    TokenHash  CHAR(64)     NOT NULL UNIQUE,
+    Scopes     VARCHAR(255) NOT NULL,
+    ExpiresAt  TIMESTAMP    NOT NULL,
+    LastUsedAt TIMESTAMP    NULL,
+    RevokedAt  TIMESTAMP    NULL,
+    CreatedAt  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_personal_access_tokens_user (UserId),
+    FOREIGN KEY (UserId) REFERENCES Users (Id) ON DELETE CASCADE
+);
@@ -0,0 +1,13 @@
+CREATE TABLE Profiles
+(
+    UserId        CHAR(36) PRIMARY KEY,
+    Course        VARCHAR(100) NOT NULL DEFAULT '',
+    Campus        VARCHAR(100) NOT NULL DEFAULT '',
+    EntryYear     SMALLINT     NOT NULL DEFAULT 0,
+    EntrySemester TINYINT      NOT NULL DEFAULT 0,
+    Bio           VARCHAR(280) NOT NULL DEFAULT '',
+    Pronouns      VARCHAR(30)  NOT NULL DEFAULT '',
+    Links         JSON         NULL,
+    LastModified  TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (UserId) REFERENCES Users (Id) ON DELETE CASCADE
+);
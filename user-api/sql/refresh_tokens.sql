@@ -0,0 +1,13 @@
+CREATE TABLE RefreshTokens
+(
+    Id        CHAR(36) PRIMARY KEY,
+    UserId    CHAR(36)    NOT NULL,
+    FamilyId  CHAR(36)    NOT NULL,
+    TokenHash CHAR(64)    NOT NULL UNIQUE,
+    ExpiresAt TIMESTAMP   NOT NULL,
+    UsedAt    TIMESTAMP   NULL,
+    RevokedAt TIMESTAMP   NULL,
+    CreatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_refresh_tokens_family (FamilyId),
+    FOREIGN KEY (UserId) REFERENCES Users (Id) ON DELETE CASCADE
+);
@@ -0,0 +1,8 @@
+CREATE TABLE ResendThrottles
+(
+    `Key`      VARCHAR(150) PRIMARY KEY,
+    LastSentAt TIMESTAMP    NOT NULL,
+    WindowEnd  TIMESTAMP    NOT NULL,
+    Count      INT          NOT NULL DEFAULT 0,
+    INDEX idx_resend_throttles_window_end (WindowEnd)
+);
@@ -0,0 +1,6 @@
+CREATE TABLE RevokedTokens
+(
+    Jti       CHAR(36) PRIMARY KEY,
+    ExpiresAt TIMESTAMP NOT NULL,
+    INDEX idx_revoked_tokens_expires_at (ExpiresAt)
+);
@@ -0,0 +1,14 @@
+CREATE TABLE Sessions
+(
+    Id         CHAR(36) PRIMARY KEY,
+    UserId     CHAR(36)     NOT NULL,
+    UserAgent  VARCHAR(512) NOT NULL,
+    IP         VARCHAR(45)  NOT NULL,
+    Scopes     VARCHAR(100) NOT NULL,
+    CreatedAt  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    LastSeenAt TIMESTAMP    NOT NULL,
+    ExpiresAt  TIMESTAMP    NOT NULL,
+    RevokedAt  TIMESTAMP    NULL,
+    INDEX idx_sessions_user (UserId),
+    FOREIGN KEY (UserId) REFERENCES Users (Id) ON DELETE CASCADE
+);
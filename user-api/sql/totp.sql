@@ -0,0 +1,20 @@
+CREATE TABLE UserTOTP
+(
+    UserId       CHAR(36) PRIMARY KEY,
+    Secret       VARCHAR(64) NOT NULL,
+    Enabled      BOOLEAN     NOT NULL DEFAULT FALSE,
+    LastUsedStep BIGINT      NOT NULL DEFAULT 0,
+    CreatedAt    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (UserId) REFERENCES Users (Id) ON DELETE CASCADE
+);
+
+CREATE TABLE RecoveryCodes
+(
+    Id        CHAR(36) PRIMARY KEY,
+    UserId    CHAR(36) NOT NULL,
+    CodeHash  CHAR(64) NOT NULL,
+    UsedAt    TIMESTAMP NULL,
+    CreatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_recovery_codes_user (UserId),
+    FOREIGN KEY (UserId) REFERENCES Users (Id) ON DELETE CASCADE
+);
@@ -1,10 +1,16 @@
 CREATE TABLE Users
 (
-    Id               CHAR(36) PRIMARY KEY,
-    Name             VARCHAR(70)  NOT NULL,
-    Email            VARCHAR(100) NOT NULL UNIQUE,
-    Password         VARCHAR(255) NOT NULL,
-    CreatedAt        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    IsEmailConfirmed BOOLEAN   DEFAULT FALSE,
-    LastModified     TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+    Id                CHAR(36) PRIMARY KEY,
+    Name              VARCHAR(70)  NOT NULL,
+    Email             VARCHAR(100) NOT NULL UNIQUE,
+    Password          VARCHAR(255) NOT NULL,
+    Role              VARCHAR(20)  NOT NULL DEFAULT 'user',
+    Affiliation       VARCHAR(20)  NOT NULL DEFAULT 'external',
+    Matricula         CHAR(12)     NULL UNIQUE,
+    MatriculaVerified BOOLEAN   DEFAULT FALSE,
+    AvatarId          CHAR(36)     NULL,
+    GoogleId          VARCHAR(255) NULL UNIQUE,
+    CreatedAt         TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    IsEmailConfirmed  BOOLEAN   DEFAULT FALSE,
+    LastModified      TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 );
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE WebAuthnCredentials
+(
+    Id              VARCHAR(255) PRIMARY KEY,
+    UserId          CHAR(36)     NOT NULL,
+    PublicKey       BLOB         NOT NULL,
+    AttestationType VARCHAR(32)  NOT NULL,
+    Transport       VARCHAR(100) NOT NULL DEFAULT '',
+    AAGUID          VARBINARY(16),
+    SignCount       INT UNSIGNED NOT NULL DEFAULT 0,
+    CreatedAt       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    LastUsedAt      TIMESTAMP    NULL,
+    INDEX idx_webauthn_credentials_user (UserId),
+    FOREIGN KEY (UserId) REFERENCES Users (Id) ON DELETE CASCADE
+);
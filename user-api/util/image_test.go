@@ -0,0 +1,184 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withExifOrientation inserts an APP1 segment holding only the orientation
+// tag right after the SOI marker of a JPEG.
+func withExifOrientation(t *testing.T, data []byte, order binary.AppendByteOrder, orientation uint16) []byte {
+	t.Helper()
+	require.True(t, bytes.HasPrefix(data, []byte{0xFF, 0xD8}))
+
+	tiff := []byte("MM")
+	if order == binary.LittleEndian {
+		tiff = []byte("II")
+	}
+	tiff = order.AppendUint16(tiff, 42)
+	tiff = order.AppendUint32(tiff, 8)
+	tiff = order.AppendUint16(tiff, 1)
+	tiff = order.AppendUint16(tiff, 0x0112)
+	tiff = order.AppendUint16(tiff, 3)
+	tiff = order.AppendUint32(tiff, 1)
+	tiff = order.AppendUint16(tiff, orientation)
+	tiff = order.AppendUint16(tiff, 0)
+	tiff = order.AppendUint32(tiff, 0)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := binary.BigEndian.AppendUint16([]byte{0xFF, 0xE1}, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+func TestExifOrientation(t *testing.T) {
+	plain := encodeJPEG(t, image.NewRGBA(image.Rect(0, 0, 8, 8)))
+
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected int
+	}{
+		{name: "no exif", data: plain, expected: 1},
+		{name: "big endian", data: withExifOrientation(t, plain, binary.BigEndian, 6), expected: 6},
+		{name: "little endian", data: withExifOrientation(t, plain, binary.LittleEndian, 8), expected: 8},
+		{name: "out of range", data: withExifOrientation(t, plain, binary.BigEndian, 9), expected: 1},
+		{name: "truncated", data: withExifOrientation(t, plain, binary.BigEndian, 6)[:20], expected: 1},
+		{name: "not a jpeg", data: []byte("\x89PNG\r\n\x1a\n"), expected: 1},
+		{name: "empty", data: nil, expected: 1},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, ExifOrientation(testCase.data))
+		})
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// A 3x2 image with a distinct value in every pixel.
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			src.SetRGBA(x, y, color.RGBA{R: uint8(x), G: uint8(y), A: 255})
+		}
+	}
+	topLeft, topRight := src.RGBAAt(0, 0), src.RGBAAt(2, 0)
+
+	testCases := []struct {
+		orientation int
+		size        image.Point
+		topLeft     image.Point
+		topRight    image.Point
+	}{
+		{orientation: 1, size: image.Pt(3, 2), topLeft: image.Pt(0, 0), topRight: image.Pt(2, 0)},
+		{orientation: 2, size: image.Pt(3, 2), topLeft: image.Pt(2, 0), topRight: image.Pt(0, 0)},
+		{orientation: 3, size: image.Pt(3, 2), topLeft: image.Pt(2, 1), topRight: image.Pt(0, 1)},
+		{orientation: 4, size: image.Pt(3, 2), topLeft: image.Pt(0, 1), topRight: image.Pt(2, 1)},
+		{orientation: 5, size: image.Pt(2, 3), topLeft: image.Pt(0, 0), topRight: image.Pt(0, 2)},
+		{orientation: 6, size: image.Pt(2, 3), topLeft: image.Pt(1, 0), topRight: image.Pt(1, 2)},
+		{orientation: 7, size: image.Pt(2, 3), topLeft: image.Pt(1, 2), topRight: image.Pt(1, 0)},
+		{orientation: 8, size: image.Pt(2, 3), topLeft: image.Pt(0, 2), topRight: image.Pt(0, 0)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(strconv.Itoa(testCase.orientation), func(t *testing.T) {
+			dst := ApplyOrientation(src, testCase.orientation)
+
+			assert.Equal(t, testCase.size, dst.Bounds().Size())
+			assert.Equal(t, topLeft, dst.RGBAAt(testCase.topLeft.X, testCase.topLeft.Y))
+			assert.Equal(t, topRight, dst.RGBAAt(testCase.topRight.X, testCase.topRight.Y))
+		})
+	}
+}
+
+func TestSquareThumbnailAveragesCenterCrop(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+
+	// The outer columns of the 6x4 source fall outside the square crop.
+	src := image.NewRGBA(image.Rect(0, 0, 6, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 6; x++ {
+			switch {
+			case x == 0 || x == 5:
+				src.SetRGBA(x, y, color.RGBA{A: 255})
+			case x < 3:
+				src.SetRGBA(x, y, red)
+			default:
+				src.SetRGBA(x, y, blue)
+			}
+		}
+	}
+
+	// The same pixels inside a larger buffer, with a bounds origin not at 0,0.
+	padded := image.NewRGBA(image.Rect(0, 0, 8, 6))
+	draw.Draw(padded, padded.Bounds(), image.NewUniform(color.RGBA{G: 255, A: 255}), image.Point{}, draw.Src)
+	draw.Draw(padded, image.Rect(1, 1, 7, 5), src, image.Point{}, draw.Src)
+
+	testCases := []struct {
+		name string
+		src  *image.RGBA
+	}{
+		{name: "whole image", src: src},
+		{name: "sub image", src: padded.SubImage(image.Rect(1, 1, 7, 5)).(*image.RGBA)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dst := SquareThumbnail(testCase.src, 2)
+
+			assert.Equal(t, image.Pt(2, 2), dst.Bounds().Size())
+			assert.Equal(t, red, dst.RGBAAt(0, 0))
+			assert.Equal(t, red, dst.RGBAAt(0, 1))
+			assert.Equal(t, blue, dst.RGBAAt(1, 0))
+			assert.Equal(t, blue, dst.RGBAAt(1, 1))
+		})
+	}
+
+	averaged := SquareThumbnail(src, 1)
+	assert.Equal(t, color.RGBA{R: 127, B: 127, A: 255}, averaged.RGBAAt(0, 0))
+
+	upscaled := SquareThumbnail(src, 8)
+	assert.Equal(t, red, upscaled.RGBAAt(0, 7))
+	assert.Equal(t, blue, upscaled.RGBAAt(7, 0))
+}
+
+func TestToRGBAPremultipliesAndMovesOrigin(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(10, 10, 12, 12))
+	src.SetNRGBA(10, 10, color.NRGBA{R: 255, A: 128})
+
+	dst := ToRGBA(src)
+
+	assert.Equal(t, image.Rect(0, 0, 2, 2), dst.Bounds())
+	assert.Equal(t, color.RGBA{R: 128, A: 128}, dst.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{}, dst.RGBAAt(1, 1))
+}
+
+func TestFlattenOnWhite(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+
+	dst := FlattenOnWhite(src)
+
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, dst.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, dst.RGBAAt(1, 0))
+}
@@ -0,0 +1,194 @@
+package util
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/golang-jwt/jwt"
+)
+
+type signingKey struct {
+	id         string
+	method     jwt.SigningMethod
+	privateKey crypto.PrivateKey
+	publicKey  crypto.PublicKey
+}
+
+var (
+	signingKeysMutex sync.RWMutex
+	signingKeys      = make(map[string]signingKey)
+	activeKeyId      = ""
+)
+
+// LoadSigningKeys reads every *.pem file in dir, using the file name as kid.
+// Files holding only a public key keep verifying tokens after their private
+// key was retired. The active key signs new tokens; when activeId is empty
+// the last private key in lexical order is used.
+func LoadSigningKeys(dir string, activeId string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]signingKey, len(paths))
+	privateKeyIds := make([]string, 0, len(paths))
+	for _, path := range paths {
+		key, err := readSigningKey(path)
+		if err != nil {
+			return err
+		}
+
+		keys[key.id] = key
+		if key.privateKey != nil {
+			privateKeyIds = append(privateKeyIds, key.id)
+		}
+	}
+
+	if len(privateKeyIds) == 0 {
+		return model.ErrNoSigningKeys
+	}
+
+	if activeId == "" {
+		sort.Strings(privateKeyIds)
+		activeId = privateKeyIds[len(privateKeyIds)-1]
+	}
+
+	if key, ok := keys[activeId]; !ok || key.privateKey == nil {
+		return model.ErrSigningKeyNotFound
+	}
+
+	signingKeysMutex.Lock()
+	defer signingKeysMutex.Unlock()
+
+	signingKeys = keys
+	activeKeyId = activeId
+
+	return nil
+}
+
+func readSigningKey(path string) (signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return signingKey{}, model.ErrUnsupportedSigningKey
+	}
+
+	key := signingKey{id: strings.TrimSuffix(filepath.Base(path), ".pem")}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key.privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key.privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key.publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return signingKey{}, model.ErrUnsupportedSigningKey
+	}
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	if key.privateKey != nil {
+		signer, ok := key.privateKey.(crypto.Signer)
+		if !ok {
+			return signingKey{}, model.ErrUnsupportedSigningKey
+		}
+		key.publicKey = signer.Public()
+	}
+
+	switch key.publicKey.(type) {
+	case *rsa.PublicKey:
+		key.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return signingKey{}, model.ErrUnsupportedSigningKey
+	}
+
+	return key, nil
+}
+
+func signToken(claims jwt.MapClaims) (string, error) {
+	signingKeysMutex.RLock()
+	key, ok := signingKeys[activeKeyId]
+	signingKeysMutex.RUnlock()
+
+	if !ok {
+		return "", model.ErrSigningKeyNotFound
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+
+	return token.SignedString(key.privateKey)
+}
+
+func GetVerificationKey(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok || kid == "" {
+		return nil, model.ErrSigningKeyIdNotInToken
+	}
+
+	signingKeysMutex.RLock()
+	key, ok := signingKeys[kid]
+	signingKeysMutex.RUnlock()
+
+	if !ok {
+		return nil, model.ErrSigningKeyNotFound
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, model.ErrUnexpectedSigningMethod
+	}
+
+	return key.publicKey, nil
+}
+
+func JWKS() model.JWKS {
+	signingKeysMutex.RLock()
+	defer signingKeysMutex.RUnlock()
+
+	jwks := model.JWKS{Keys: make([]model.JWK, 0, len(signingKeys))}
+	for _, key := range signingKeys {
+		jwk := model.JWK{
+			Use: "sig",
+			Kid: key.id,
+			Alg: key.method.Alg(),
+		}
+
+		switch publicKey := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+
+	return jwks
+}
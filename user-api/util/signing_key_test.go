@@ -0,0 +1,179 @@
+package util
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OVillas/user-api/model"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRSAKey(t *testing.T, dir string, kid string) *rsa.PrivateKey {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600))
+
+	return privateKey
+}
+
+func writeEd25519Key(t *testing.T, dir string, kid string, publicOnly bool) ed25519.PublicKey {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block := &pem.Block{Type: "PRIVATE KEY"}
+	if publicOnly {
+		block.Type = "PUBLIC KEY"
+		block.Bytes, err = x509.MarshalPKIXPublicKey(publicKey)
+	} else {
+		block.Bytes, err = x509.MarshalPKCS8PrivateKey(privateKey)
+	}
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pem"), pem.EncodeToMemory(block), 0o600))
+
+	return publicKey
+}
+
+func activeKid(t *testing.T) string {
+	t.Helper()
+
+	tokenString, err := signToken(jwt.MapClaims{"sub": "6f1c2a8e-3b1d-4c5e-9f7a-2d4b6c8e0a12"})
+	require.NoError(t, err)
+
+	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, jwt.MapClaims{})
+	require.NoError(t, err)
+
+	return token.Header["kid"].(string)
+}
+
+func TestLoadSigningKeysSelectsActiveKey(t *testing.T) {
+	dir := t.TempDir()
+	writeRSAKey(t, dir, "2024-01")
+	writeEd25519Key(t, dir, "2024-06", false)
+	writeEd25519Key(t, dir, "2025-01", true)
+
+	testCases := []struct {
+		name     string
+		activeId string
+		expected string
+	}{
+		{name: "last private key by default", activeId: "", expected: "2024-06"},
+		{name: "explicit active key", activeId: "2024-01", expected: "2024-01"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.NoError(t, LoadSigningKeys(dir, testCase.activeId))
+			assert.Equal(t, testCase.expected, activeKid(t))
+		})
+	}
+}
+
+func TestLoadSigningKeysRejectsUnusableActiveKey(t *testing.T) {
+	dir := t.TempDir()
+	writeRSAKey(t, dir, "2024-01")
+	writeEd25519Key(t, dir, "2025-01", true)
+
+	assert.ErrorIs(t, LoadSigningKeys(dir, "2025-01"), model.ErrSigningKeyNotFound)
+	assert.ErrorIs(t, LoadSigningKeys(dir, "2030-01"), model.ErrSigningKeyNotFound)
+}
+
+func TestLoadSigningKeysRequiresAPrivateKey(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2025-01", true)
+
+	assert.ErrorIs(t, LoadSigningKeys(dir, ""), model.ErrNoSigningKeys)
+}
+
+func TestJWKSListsEveryKeyByKid(t *testing.T) {
+	dir := t.TempDir()
+	retiredKey := writeEd25519Key(t, dir, "2025-01", true)
+	rsaKey := writeRSAKey(t, dir, "2024-01")
+	ed25519Key := writeEd25519Key(t, dir, "2024-06", false)
+	require.NoError(t, LoadSigningKeys(dir, ""))
+
+	jwks := JWKS()
+
+	require.Len(t, jwks.Keys, 3)
+	assert.Equal(t, model.JWK{
+		Kty: "RSA",
+		Use: "sig",
+		Kid: "2024-01",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		E:   "AQAB",
+	}, jwks.Keys[0])
+	assert.Equal(t, model.JWK{
+		Kty: "OKP",
+		Use: "sig",
+		Kid: "2024-06",
+		Alg: "EdDSA",
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(ed25519Key),
+	}, jwks.Keys[1])
+	assert.Equal(t, model.JWK{
+		Kty: "OKP",
+		Use: "sig",
+		Kid: "2025-01",
+		Alg: "EdDSA",
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(retiredKey),
+	}, jwks.Keys[2])
+}
+
+func TestGetVerificationKey(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := writeRSAKey(t, dir, "2024-01")
+	ed25519Key := writeEd25519Key(t, dir, "2024-06", false)
+	require.NoError(t, LoadSigningKeys(dir, ""))
+
+	testCases := []struct {
+		name     string
+		method   jwt.SigningMethod
+		kid      interface{}
+		expected interface{}
+		err      error
+	}{
+		{name: "rsa key", method: jwt.SigningMethodRS256, kid: "2024-01", expected: &rsaKey.PublicKey},
+		{name: "ed25519 key", method: jwt.SigningMethodEdDSA, kid: "2024-06", expected: ed25519Key},
+		{name: "alg does not match kid", method: jwt.SigningMethodRS256, kid: "2024-06", err: model.ErrUnexpectedSigningMethod},
+		{name: "hmac with rsa kid", method: jwt.SigningMethodHS256, kid: "2024-01", err: model.ErrUnexpectedSigningMethod},
+		{name: "unknown kid", method: jwt.SigningMethodEdDSA, kid: "2030-01", err: model.ErrSigningKeyNotFound},
+		{name: "missing kid", method: jwt.SigningMethodEdDSA, kid: nil, err: model.ErrSigningKeyIdNotInToken},
+		{name: "kid is not a string", method: jwt.SigningMethodEdDSA, kid: 2024, err: model.ErrSigningKeyIdNotInToken},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			token := jwt.New(testCase.method)
+			if testCase.kid != nil {
+				token.Header["kid"] = testCase.kid
+			}
+
+			key, err := GetVerificationKey(token)
+			if testCase.err != nil {
+				assert.ErrorIs(t, err, testCase.err)
+				assert.Nil(t, key)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, key)
+		})
+	}
+}
@@ -2,9 +2,13 @@ package util
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"github.com/OVillas/user-api/config"
 	"github.com/OVillas/user-api/model"
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"io"
 	"strings"
@@ -13,16 +17,39 @@ import (
 
 var table = [...]byte{'1', '2', '3', '4', '5', '6', '7', '8', '9', '0'}
 
-func CreateToken(user model.User) (string, error) {
+const (
+	TokenTypeAccess     = "access"
+	TokenTypeMFAPending = "mfa_pending"
+	TokenTypeOIDCState  = "oidc_state"
+	TokenTypePersonal   = "personal_access"
+	TokenTypeMagicLink  = "magic_link"
+	PersonalTokenPrefix = "cuat_"
+	mfaTokenTTL         = 5 * time.Minute
+	MagicLinkTokenTTL   = 15 * time.Minute
+	principalContextKey = "principal"
+)
+
+// CreateToken issues an access token carrying the given scopes; callers
+// narrow them from UserScopes, never widen.
+func CreateToken(user model.User, sessionId string, scopes []string) (string, error) {
+	jti, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	tokenString, err := signToken(jwt.MapClaims{
+		"jti":   jti.String(),
+		"iss":   config.JWTIssuer,
+		"aud":   config.JWTAudience,
+		"typ":   TokenTypeAccess,
+		"sid":   sessionId,
 		"id":    user.Id,
 		"name":  user.Name,
 		"email": user.Email,
-		"exp":   time.Now().Add(time.Hour * 6).Unix(),
+		"roles": []string{userRole(user)},
+		"scope": strings.Join(scopes, " "),
+		"exp":   time.Now().Add(config.AccessTokenTTL).Unix(),
 	})
-
-	tokenString, err := token.SignedString([]byte(config.SecretKey))
 	if err != nil {
 		return "", err
 	}
@@ -30,52 +57,216 @@ func CreateToken(user model.User) (string, error) {
 	return tokenString, nil
 }
 
-func getVerificationKey(token *jwt.Token) (interface{}, error) {
-	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-		return nil, model.ErrUnexpectedSigningMethod
+func CreateMFAToken(user model.User, scopes []string) (string, error) {
+	jti, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+
+	return signToken(jwt.MapClaims{
+		"jti":   jti.String(),
+		"iss":   config.JWTIssuer,
+		"aud":   config.JWTAudience,
+		"typ":   TokenTypeMFAPending,
+		"id":    user.Id,
+		"scope": strings.Join(scopes, " "),
+		"exp":   time.Now().Add(mfaTokenTTL).Unix(),
+	})
+}
+
+func CreateMagicLinkToken(user model.User) (string, error) {
+	jti, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
 	}
 
-	return config.SecretKey, nil
+	return signToken(jwt.MapClaims{
+		"jti":   jti.String(),
+		"iss":   config.JWTIssuer,
+		"aud":   config.JWTAudience,
+		"typ":   TokenTypeMagicLink,
+		"id":    user.Id,
+		"email": user.Email,
+		"exp":   time.Now().Add(MagicLinkTokenTTL).Unix(),
+	})
+}
+
+// CreateOIDCStateToken signs the state, nonce and PKCE verifier of a pending
+// Google login so they can live in a cookie of the browser that started it.
+func CreateOIDCStateToken(oidcState model.OIDCState) (string, error) {
+	return signToken(jwt.MapClaims{
+		"iss":      config.JWTIssuer,
+		"aud":      config.JWTAudience,
+		"typ":      TokenTypeOIDCState,
+		"state":    oidcState.State,
+		"nonce":    oidcState.Nonce,
+		"verifier": oidcState.CodeVerifier,
+		"exp":      oidcState.ExpiryTime.Unix(),
+	})
 }
 
-func extractToken(c echo.Context) string {
-	token := c.Request().Header.Get("Authorization")
+func ParseOIDCStateToken(tokenString string) (*model.OIDCState, error) {
+	token, err := jwt.Parse(tokenString, GetVerificationKey)
+	if err != nil {
+		return nil, model.ErrOIDCInvalidState
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, model.ErrOIDCInvalidState
+	}
+
+	if !claims.VerifyIssuer(config.JWTIssuer, true) || !claims.VerifyAudience(config.JWTAudience, true) {
+		return nil, model.ErrOIDCInvalidState
+	}
+
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, model.ErrOIDCInvalidState
+	}
+
+	if typ, _ := claims["typ"].(string); typ != TokenTypeOIDCState {
+		return nil, model.ErrOIDCInvalidState
+	}
 
-	length := len(strings.Split(token, " "))
-	if length == 2 {
-		return strings.Split(token, " ")[1]
+	state, _ := claims["state"].(string)
+	nonce, _ := claims["nonce"].(string)
+	verifier, _ := claims["verifier"].(string)
+	if state == "" || nonce == "" || verifier == "" {
+		return nil, model.ErrOIDCInvalidState
 	}
 
-	return ""
+	exp, _ := claims["exp"].(float64)
+
+	return &model.OIDCState{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiryTime:   time.Unix(int64(exp), 0),
+	}, nil
 }
 
-func ExtractUserIdFromToken(c echo.Context) (string, error) {
-	tokenString := extractToken(c)
-	token, err := jwt.Parse(tokenString, getVerificationKey)
+func ParseMFAToken(tokenString string) (*model.Principal, error) {
+	principal, err := ParseToken(tokenString, TokenTypeMFAPending)
 	if err != nil {
-		return "", err
+		return nil, model.ErrInvalidMFAToken
+	}
+
+	return principal, nil
+}
+
+func ParseToken(tokenString string, tokenType string) (*model.Principal, error) {
+	token, err := jwt.Parse(tokenString, GetVerificationKey)
+	if err != nil {
+		return nil, err
 	}
 
-	permissions, ok := token.Claims.(jwt.MapClaims)
-	if !ok && !token.Valid {
-		return "", model.ErrInvalidToken
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, model.ErrInvalidToken
 	}
 
-	idInterface, exists := permissions["id"]
-	if !exists {
-		return "", model.ErrIdNotFoundInPermissions
+	if !claims.VerifyIssuer(config.JWTIssuer, true) || !claims.VerifyAudience(config.JWTAudience, true) {
+		return nil, model.ErrInvalidToken
 	}
 
-	id, ok := idInterface.(string)
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, model.ErrInvalidToken
+	}
+
+	if typ, _ := claims["typ"].(string); typ != tokenType {
+		return nil, model.ErrInvalidToken
+	}
+
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return nil, model.ErrTokenIdNotFound
+	}
+
+	id, ok := claims["id"].(string)
 	if !ok {
-		return "", model.ErrIdIsNotAString
+		return nil, model.ErrIdNotFoundInPermissions
 	}
 
 	if err := IsValidUUID(id); err != nil {
-		return "", model.ErrInvalidId
+		return nil, model.ErrInvalidId
+	}
+
+	exp, _ := claims["exp"].(float64)
+	sid, _ := claims["sid"].(string)
+	name, _ := claims["name"].(string)
+	email, _ := claims["email"].(string)
+
+	scope, _ := claims["scope"].(string)
+
+	roles := []string{}
+	if rolesInterface, ok := claims["roles"].([]interface{}); ok {
+		for _, roleInterface := range rolesInterface {
+			role, ok := roleInterface.(string)
+			if !ok {
+				return nil, model.ErrRolesNotFoundInPermissions
+			}
+			roles = append(roles, role)
+		}
+	}
+
+	return &model.Principal{
+		Id:        id,
+		Name:      name,
+		Email:     email,
+		Roles:     roles,
+		Scopes:    strings.Fields(scope),
+		TokenId:   jti,
+		TokenType: tokenType,
+		SessionId: sid,
+		ExpiresAt: time.Unix(int64(exp), 0),
+	}, nil
+}
+
+func userRole(user model.User) string {
+	if user.Role == "" {
+		return model.RoleUser
+	}
+
+	return user.Role
+}
+
+// UserScopes lists every scope the role of the user may hold.
+func UserScopes(user model.User) []string {
+	scopes := []string{model.ScopeUserRead, model.ScopeUserWrite}
+	if userRole(user) == model.RoleAdmin {
+		scopes = append(scopes, model.ScopeAdmin)
 	}
 
-	return id, nil
+	return scopes
+}
+
+func ExtractBearerToken(c echo.Context) string {
+	parts := strings.Split(c.Request().Header.Get("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return ""
+	}
+
+	return parts[1]
+}
+
+func ExtractClientInfo(c echo.Context) model.ClientInfo {
+	return model.ClientInfo{
+		IP:        c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}
+}
+
+func SetPrincipal(c echo.Context, principal *model.Principal) {
+	c.Set(principalContextKey, principal)
+}
+
+func GetPrincipal(c echo.Context) (*model.Principal, error) {
+	principal, ok := c.Get(principalContextKey).(*model.Principal)
+	if !ok || principal == nil {
+		return nil, model.ErrPrincipalNotFound
+	}
+
+	return principal, nil
 }
 
 func GenerateOTP(max int) string {
@@ -89,3 +280,21 @@ func GenerateOTP(max int) string {
 	}
 	return string(b)
 }
+
+func GenerateRefreshToken() (string, error) {
+	return GenerateRandomToken(32)
+}
+
+func GenerateRandomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
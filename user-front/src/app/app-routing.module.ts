@@ -5,6 +5,8 @@ import { RegisterComponent } from './components/authentication/register/register
 import { HomeComponent } from './components/home/home.component';
 import { LoginEmailComponent } from './components/authentication/login-email/login-email.component';
 import { LoginPasswordComponent } from './components/authentication/login-password/login-password.component';
+import { LoginMagicLinkComponent } from './components/authentication/login-magic-link/login-magic-link.component';
+import { EmailChangeCancelComponent } from './components/authentication/email-change-cancel/email-change-cancel.component';
 
 const routes: Routes = [
 
@@ -17,6 +19,8 @@ const routes: Routes = [
     children: [
       { path: "login/enter-email", component:LoginEmailComponent},
       { path: "login/enter-password", component: LoginPasswordComponent},
+      { path: "login/magic-link", component: LoginMagicLinkComponent},
+      { path: "email-change/cancel", component: EmailChangeCancelComponent},
       { path: "register", component: RegisterComponent}
     ]
   }
@@ -10,6 +10,8 @@ import { RegisterComponent } from './components/authentication/register/register
 import { HomeComponent } from './components/home/home.component';
 import { LoginPasswordComponent } from './components/authentication/login-password/login-password.component';
 import { LoginEmailComponent } from './components/authentication/login-email/login-email.component';
+import { LoginMagicLinkComponent } from './components/authentication/login-magic-link/login-magic-link.component';
+import { EmailChangeCancelComponent } from './components/authentication/email-change-cancel/email-change-cancel.component';
 import { NavComponent } from './components/shared/nav/nav.component';
 import { FooterComponent } from './components/shared/footer/footer.component';
 
@@ -21,6 +23,8 @@ import { FooterComponent } from './components/shared/footer/footer.component';
     HomeComponent,
     LoginPasswordComponent,
     LoginEmailComponent,
+    LoginMagicLinkComponent,
+    EmailChangeCancelComponent,
     NavComponent,
     FooterComponent,
   ],
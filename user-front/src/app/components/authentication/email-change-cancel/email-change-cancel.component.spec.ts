@@ -0,0 +1,21 @@
+import { ComponentFixture, TestBed } from '@angular/core/testing';
+
+import { EmailChangeCancelComponent } from './email-change-cancel.component';
+
+describe('EmailChangeCancelComponent', () => {
+  let component: EmailChangeCancelComponent;
+  let fixture: ComponentFixture<EmailChangeCancelComponent>;
+
+  beforeEach(() => {
+    TestBed.configureTestingModule({
+      declarations: [EmailChangeCancelComponent]
+    });
+    fixture = TestBed.createComponent(EmailChangeCancelComponent);
+    component = fixture.componentInstance;
+    fixture.detectChanges();
+  });
+
+  it('should create', () => {
+    expect(component).toBeTruthy();
+  });
+});
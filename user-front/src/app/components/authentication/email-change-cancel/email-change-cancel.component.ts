@@ -0,0 +1,36 @@
+import { Component, OnInit } from '@angular/core';
+import { ActivatedRoute } from '@angular/router';
+import { UserService } from 'src/services/user/user.service';
+
+@Component({
+  selector: 'app-email-change-cancel',
+  templateUrl: './email-change-cancel.component.html',
+  styleUrls: ['./email-change-cancel.component.scss']
+})
+export class EmailChangeCancelComponent implements OnInit {
+  public canceled: boolean = false
+  public invalidLink: boolean = false
+
+  constructor(
+    private route: ActivatedRoute,
+    private userService: UserService) { }
+
+  ngOnInit(): void {
+    const token = this.route.snapshot.queryParamMap.get('token')
+    if (!token) {
+      this.invalidLink = true;
+      return
+    }
+
+    this.userService.cancelEmailChange(token)
+      .subscribe({
+        next: () => {
+          this.canceled = true;
+        },
+        error: () => {
+          this.invalidLink = true;
+        }
+      })
+  }
+
+}
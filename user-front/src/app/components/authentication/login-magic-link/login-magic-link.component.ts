@@ -0,0 +1,43 @@
+import { Component, OnInit } from '@angular/core';
+import { ActivatedRoute, Router } from '@angular/router';
+import { CookieService } from 'ngx-cookie-service';
+import { TokenPair } from 'src/models/authentication/tokenPair';
+import { AuthenticationService } from 'src/services/authentication/authentication.service';
+
+@Component({
+  selector: 'app-login-magic-link',
+  templateUrl: './login-magic-link.component.html',
+  styleUrls: ['./login-magic-link.component.scss']
+})
+export class LoginMagicLinkComponent implements OnInit {
+  public invalidLink: boolean = false
+
+  constructor(
+    private route: ActivatedRoute,
+    private router: Router,
+    private authService: AuthenticationService,
+    private cookieService: CookieService) { }
+
+  ngOnInit(): void {
+    const token = this.route.snapshot.queryParamMap.get('token')
+    if (!token) {
+      this.invalidLink = true;
+      return
+    }
+
+    this.cookieService.deleteAll();
+
+    this.authService.MagicLinkLogin(token)
+      .subscribe({
+        next: (response: TokenPair) => {
+          this.cookieService.set("token", response.accessToken)
+          this.cookieService.set("refreshToken", response.refreshToken)
+          this.router.navigate(['/home']);
+        },
+        error: () => {
+          this.invalidLink = true;
+        }
+      })
+  }
+
+}
@@ -3,6 +3,7 @@ import { FormBuilder, FormControl, FormGroup, Validators } from '@angular/forms'
 import { Router } from '@angular/router';
 import { CookieService } from 'ngx-cookie-service';
 import { Login } from 'src/models/authentication/login';
+import { TokenPair } from 'src/models/authentication/tokenPair';
 import { AuthenticationService } from 'src/services/authentication/authentication.service';
 
 @Component({
@@ -15,6 +16,7 @@ export class LoginPasswordComponent implements OnInit {
   public form!: FormGroup;
   public invalidPassword: boolean = false
   public invalidData: boolean = false
+  public magicLinkSent: boolean = false
 
   get f(): any {
     return this.form.controls
@@ -58,8 +60,9 @@ export class LoginPasswordComponent implements OnInit {
       const login = new Login(this.email, password)
       this.authService.Login(login)
         .subscribe({
-          next: (response: string) => {
-            this.cookieService.set("token", response)
+          next: (response: TokenPair) => {
+            this.cookieService.set("token", response.accessToken)
+            this.cookieService.set("refreshToken", response.refreshToken)
             this.router.navigate(['/home']);
           },
           error: (error: any) => {
@@ -73,6 +76,15 @@ export class LoginPasswordComponent implements OnInit {
     }
   }
 
+  public sendMagicLink(): void {
+    this.authService.SendMagicLink(this.email)
+      .subscribe({
+        next: () => {
+          this.magicLinkSent = true;
+        }
+      })
+  }
+
   public cssValidator(campoForm: FormControl): any {
     return { 'is-invalid': (campoForm?.errors && (campoForm?.touched || campoForm?.dirty)) || this.invalidPassword };
   }
@@ -0,0 +1,11 @@
+export class TokenPair {
+  accessToken: string;
+  refreshToken: string;
+  expiresIn: number;
+
+  constructor(accessToken: string, refreshToken: string, expiresIn: number) {
+    this.accessToken = accessToken;
+    this.refreshToken = refreshToken;
+    this.expiresIn = expiresIn;
+  }
+}
@@ -1,4 +1,5 @@
 import { Login } from './../../models/authentication/login';
+import { TokenPair } from './../../models/authentication/tokenPair';
 import { HttpClient } from '@angular/common/http';
 import { Injectable } from '@angular/core';
 import { Observable } from 'rxjs';
@@ -12,8 +13,20 @@ export class AuthenticationService {
 
   constructor(private http: HttpClient) { }
 
-  public Login(login: Login): Observable<string> {
-    return this.http.post<string>(`${this.userAPI}/authentication/login`, login)
+  public Login(login: Login): Observable<TokenPair> {
+    return this.http.post<TokenPair>(`${this.userAPI}/authentication/login`, login)
+  }
+
+  public Refresh(refreshToken: string): Observable<TokenPair> {
+    return this.http.post<TokenPair>(`${this.userAPI}/authentication/refresh`, { refreshToken })
+  }
+
+  public SendMagicLink(email: string): Observable<void> {
+    return this.http.post<void>(`${this.userAPI}/authentication/magic-link`, { email })
+  }
+
+  public MagicLinkLogin(token: string): Observable<TokenPair> {
+    return this.http.post<TokenPair>(`${this.userAPI}/authentication/magic-link/verify`, { token })
   }
 
 }
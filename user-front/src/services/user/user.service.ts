@@ -18,4 +18,8 @@ export class UserService {
     return this.http.get<UserResponse>(`${this.userAPI}/user/email?e=${email}`)
   }
 
+  public cancelEmailChange(token: string): Observable<void> {
+    return this.http.post<void>(`${this.userAPI}/user/email/cancel`, { token })
+  }
+
 }